@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/olm"
+)
+
+// almExamplesCmd regenerates the alm-examples annotation of an OLM bundle.
+var almExamplesCmd = &cobra.Command{
+	Use:   "alm-examples",
+	Short: "Regenerate the alm-examples annotation of the ClusterServiceVersion in an OLM bundle from samples of its CRDs.",
+	RunE:  runALMExamples,
+}
+
+type almExamplesCmdArgs struct {
+	minimal bool
+	stdOut  bool
+}
+
+var almExamplesArgs = &almExamplesCmdArgs{}
+
+func init() {
+	generateCmd.AddCommand(almExamplesCmd)
+	f := almExamplesCmd.Flags()
+	f.BoolVarP(&almExamplesArgs.minimal, "minimal", "l", false, "If set, the examples only contain the required fields.")
+	f.BoolVarP(&almExamplesArgs.stdOut, "stdout", "s", false, "If set, the updated ClusterServiceVersion is written to stdout instead of in place.")
+}
+
+func runALMExamples(_ *cobra.Command, _ []string) error {
+	if args.olmBundle == "" {
+		return errors.New("olm-bundle must be set")
+	}
+
+	bundle, err := olm.Load(args.olmBundle)
+	if err != nil {
+		return err
+	}
+
+	schemaTypes, err := pkg.DecodeRenderedSchemaTypes(bundle.CRDContents(), args.olmBundle, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("failed to extract schema types: %w", err)
+	}
+
+	examples, err := bundle.GenerateExamples(schemaTypes, almExamplesArgs.minimal)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(bundle.CSVPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", bundle.CSVPath, err)
+	}
+
+	updated, err := olm.SetExamples(content, examples)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", bundle.CSVPath, err)
+	}
+
+	if almExamplesArgs.stdOut {
+		_, err := os.Stdout.Write(updated)
+
+		return err
+	}
+
+	info, err := os.Stat(bundle.CSVPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", bundle.CSVPath, err)
+	}
+
+	if err := os.WriteFile(bundle.CSVPath, updated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", bundle.CSVPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/archive"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+)
+
+// ArchiveHandler reads CRDs from a tar, tar.gz or zip archive on disk or behind a URL.
+type ArchiveHandler struct {
+	location  string
+	username  string
+	password  string
+	token     string
+	group     string
+	filter    *filter.Filter
+	cache     *cache.Cache
+	client    *http.Client
+	providers []credentials.Provider
+}
+
+// CRDs returns schemas of every CRD document in the archive that matches the filter.
+func (h *ArchiveHandler) CRDs() ([]*pkg.SchemaType, error) {
+	r, err := h.open()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	var crds []*pkg.SchemaType
+
+	// entries are selected by name, so large binaries of release archives are never read.
+	match := func(name string) bool {
+		if !h.filter.Match(name) {
+			return false
+		}
+
+		if !filter.Candidate(name) {
+			_, _ = fmt.Fprintln(os.Stderr, "skipping file "+name)
+
+			return false
+		}
+
+		return true
+	}
+
+	err = archive.Walk(r, match, func(name string, content []byte) error {
+		// files without an extension are only kept if their content is a CRD, so they are checked quietly.
+		log := io.Writer(os.Stderr)
+		if !filter.HasExtension(name) {
+			log = io.Discard
+		}
+
+		schemaTypes, err := pkg.DecodeSchemaTypes(content, name, log)
+		if err != nil {
+			return skipUndecodable(log, name, err)
+		}
+
+		setGroup(schemaTypes, h.group)
+		crds = append(crds, schemaTypes...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", credentials.RedactURL(h.location), err)
+	}
+
+	return crds, nil
+}
+
+func (h *ArchiveHandler) open() (io.ReadCloser, error) {
+	if strings.HasPrefix(h.location, "http://") || strings.HasPrefix(h.location, "https://") {
+		creds, err := credentials.Resolve(h.location, credentials.Credentials{
+			Username: h.username,
+			Password: h.password,
+			Token:    h.token,
+		}, h.providers...)
+		if err != nil {
+			return nil, err
+		}
+
+		f := fetcher.NewFetcher(h.client, creds.Username, creds.Password, creds.Token).WithCache(h.cache)
+
+		// the archive is streamed, so only the matching entries are held in memory.
+		body, err := f.Open(h.location)
+		if err != nil {
+			return nil, creds.RedactError(fmt.Errorf("failed to fetch content: %w", err))
+		}
+
+		return body, nil
+	}
+
+	file, err := os.Open(filepath.Clean(h.location))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	return file, nil
+}
@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/build"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/config"
+)
+
+// buildCmd renders every output of a config file from a single load of its sources.
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Render every output of the config file incrementally.",
+	Long: `Load the sources of the config file once and render every one of its outputs. Files whose
+CRDs and render options didn't change since the previous build are skipped. The files of the build
+are recorded in a manifest, and files of the previous build that aren't produced anymore are removed.`,
+	RunE: runBuild,
+}
+
+type buildCmdArgs struct {
+	manifest string
+	force    bool
+}
+
+var buildArgs = &buildCmdArgs{}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+	// the source flags of generate are added in its init, once they are defined.
+	f := buildCmd.Flags()
+	f.StringVar(&buildArgs.manifest, "manifest", "cty-manifest.json", "The location of the manifest of the build. The manifest of the previous build is read from it.")
+	f.BoolVar(&buildArgs.force, "force", false, "Render every file, even if its inputs didn't change since the previous build.")
+	f.BoolVarP(&crdArgs.comments, "comments", "m", false, "If set, it will add descriptions as comments to each line where available.")
+	f.BoolVarP(&crdArgs.minimal, "minimal", "l", false, "If set, only the minimal required example yaml is generated.")
+	f.BoolVar(&crdArgs.skipRandom, "no-random", false, "Skip generating random values that satisfy the property patterns.")
+	f.StringVar(&crdArgs.cssFile, "css-file", "", "Path to a custom CSS file to inject into html outputs that don't set their own.")
+	f.BoolVar(&crdArgs.diagram, "diagram", false, "If set, a Mermaid diagram of the schema structure is embedded into html and markdown output.")
+	f.IntVar(&crdArgs.depth, "diagram-depth", 3, "The number of nested object levels to expand in embedded diagrams. 0 expands everything.")
+}
+
+func runBuild(_ *cobra.Command, _ []string) error {
+	if args.configFileLocation == "" {
+		return errors.New("build requires a config file")
+	}
+
+	crdHandler, err := constructHandler(args)
+	if err != nil {
+		return err
+	}
+
+	h, ok := crdHandler.(*ConfigHandler)
+	if !ok {
+		return errors.New("build only supports the config file as a source")
+	}
+
+	configFile, err := h.load()
+	if err != nil {
+		return err
+	}
+
+	if len(configFile.Outputs) == 0 {
+		return fmt.Errorf("config file %s doesn't define any outputs", args.configFileLocation)
+	}
+
+	crds, err := loadCRDs(h)
+	if err != nil {
+		return err
+	}
+
+	previous, err := build.LoadManifest(buildArgs.manifest)
+	if err != nil {
+		return err
+	}
+
+	b := build.New(previous, Version, buildArgs.force)
+	if err := buildOutputs(b, crds, configFile.Outputs); err != nil {
+		return err
+	}
+
+	manifest, err := b.Finish()
+	if err != nil {
+		return err
+	}
+
+	if err := manifest.Write(buildArgs.manifest); err != nil {
+		return err
+	}
+
+	built, unchanged := manifest.Counts()
+	_, _ = fmt.Fprintf(os.Stderr, "Built %d files, %d unchanged, %d removed\n", built, unchanged, len(manifest.Removed))
+
+	return nil
+}
+
+// buildInputs are the inputs of every file besides the CRDs it's rendered from. A new version or
+// different render options render every file again.
+type buildInputs struct {
+	Version string
+	Format  string
+	Options pkg.RenderOpts
+}
+
+// buildOutputs renders every output with the builder.
+func buildOutputs(b *build.Builder, crds []*pkg.SchemaType, outputs []config.Output) error {
+	// the CRDs are hashed once, before they are rendered into any output.
+	hashes := make(map[*pkg.SchemaType]string, len(crds))
+
+	for _, crd := range crds {
+		hash, err := build.Hash(crd)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", crd.Kind, err)
+		}
+
+		hashes[crd] = hash
+	}
+
+	for _, output := range outputs {
+		selected := crds
+		if len(output.Groups) > 0 {
+			selected = slices.DeleteFunc(slices.Clone(crds), func(crd *pkg.SchemaType) bool {
+				return !slices.Contains(output.Groups, crd.Rendering.Group)
+			})
+		}
+
+		if err := buildOutput(b, selected, hashes, output); err != nil {
+			return fmt.Errorf("failed to render %s output %s: %w", output.Format, output.Path, err)
+		}
+
+		_, _ = fmt.Fprintf(os.Stderr, "Rendered %s output %s with %d CRDs\n", output.Format, output.Path, len(selected))
+	}
+
+	return nil
+}
+
+func buildOutput(b *build.Builder, crds []*pkg.SchemaType, hashes map[*pkg.SchemaType]string, output config.Output) error {
+	opts := pkg.RenderOpts{
+		Comments:     crdArgs.comments,
+		Minimal:      crdArgs.minimal,
+		Random:       crdArgs.skipRandom,
+		Diagram:      crdArgs.diagram,
+		DiagramDepth: crdArgs.depth,
+	}
+
+	set := make([]string, 0, len(crds))
+	for _, crd := range crds {
+		set = append(set, hashes[crd])
+	}
+
+	// file returns a file of the output whose input hash covers the inputs and the given CRDs.
+	file := func(path string, crd *pkg.SchemaType, values ...any) (build.File, error) {
+		hash, err := build.Hash(append([]any{buildInputs{Version: Version, Format: output.Format, Options: opts}}, values...)...)
+		if err != nil {
+			return build.File{}, err
+		}
+
+		f := build.File{Path: path, Format: output.Format, InputHash: hash}
+		if crd != nil {
+			f.Kind = crd.Kind
+			f.Group = crd.Group
+		}
+
+		return f, nil
+	}
+
+	switch output.Format {
+	case config.FormatHTML:
+		return buildHTML(b, crds, opts, output, func(css string) (build.File, error) {
+			return file(output.Path, nil, css, set)
+		})
+	case config.FormatMarkdown:
+		for _, crd := range crds {
+			values := []any{hashes[crd]}
+			// diagrams link to the related CRDs of the page.
+			if opts.Diagram {
+				values = append(values, set)
+			}
+
+			f, err := file(filepath.Join(output.Path, filepath.FromSlash(pkg.MarkdownPagePath(crd))), crd, values...)
+			if err != nil {
+				return err
+			}
+
+			if err := b.File(f, func(w io.Writer) error {
+				return pkg.RenderMarkdown(w, crd, crds, opts)
+			}); err != nil {
+				return err
+			}
+		}
+
+		f, err := file(filepath.Join(output.Path, pkg.MarkdownIndex), nil, set)
+		if err != nil {
+			return err
+		}
+
+		return b.File(f, func(w io.Writer) error {
+			return pkg.RenderMarkdownIndex(w, crds)
+		})
+	case config.FormatYAML:
+		for _, crd := range crds {
+			f, err := file(filepath.Join(output.Path, crd.Kind+"_sample.yaml"), crd, hashes[crd])
+			if err != nil {
+				return err
+			}
+
+			if err := b.File(f, func(w io.Writer) error {
+				opts := crd.Rendering.Options(opts)
+
+				return pkg.Generate(crd, nopCloser{Writer: w}, opts.Comments, opts.Minimal, opts.Random)
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case config.FormatSchema:
+		return buildSchemas(b, crds, hashes, output, file)
+	case config.FormatValidation:
+		return buildValidation(b, crds, hashes, output, file)
+	default:
+		return fmt.Errorf("unknown format %s", output.Format)
+	}
+}
+
+func buildHTML(b *build.Builder, crds []*pkg.SchemaType, opts pkg.RenderOpts, output config.Output, file func(css string) (build.File, error)) error {
+	if err := pkg.LoadTemplates(); err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	if cssFile := cmp.Or(output.CSSFile, crdArgs.cssFile); cssFile != "" {
+		css, err := pkg.SanitizeCSS(cssFile)
+		if err != nil {
+			return fmt.Errorf("failed to process CSS file: %w", err)
+		}
+
+		opts.CustomCSS = css
+	}
+
+	f, err := file(opts.CustomCSS)
+	if err != nil {
+		return err
+	}
+
+	return b.File(f, func(w io.Writer) error {
+		return pkg.RenderContent(nopCloser{Writer: w}, crds, opts)
+	})
+}
+
+func buildSchemas(
+	b *build.Builder,
+	crds []*pkg.SchemaType,
+	hashes map[*pkg.SchemaType]string,
+	output config.Output,
+	file func(path string, crd *pkg.SchemaType, values ...any) (build.File, error),
+) error {
+	for _, crd := range crds {
+		schemas, err := versionSchemas(crd)
+		if err != nil {
+			return err
+		}
+
+		for _, schema := range schemas {
+			f, err := file(filepath.Join(output.Path, schema.name), crd, hashes[crd], schema.version)
+			if err != nil {
+				return err
+			}
+
+			if err := b.File(f, func(w io.Writer) error {
+				_, err := w.Write(schema.content)
+
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildValidation renders the reports of the changes between consecutive versions of every CRD with
+// more than one version.
+func buildValidation(
+	b *build.Builder,
+	crds []*pkg.SchemaType,
+	hashes map[*pkg.SchemaType]string,
+	output config.Output,
+	file func(path string, crd *pkg.SchemaType, values ...any) (build.File, error),
+) error {
+	validator := pkg.NewSchemaValidator()
+
+	for _, crd := range crds {
+		if len(crd.Versions) < 2 { //nolint:mnd // a report needs two versions
+			continue
+		}
+
+		name := crd.Kind + "." + crd.Group + ".validation.json"
+		if crd.Group == "" {
+			name = crd.Kind + ".validation.json"
+		}
+
+		f, err := file(filepath.Join(output.Path, name), crd, hashes[crd])
+		if err != nil {
+			return err
+		}
+
+		if err := b.File(f, func(w io.Writer) error {
+			reports := make([]*pkg.ValidationReport, 0, len(crd.Versions)-1)
+
+			for i := 1; i < len(crd.Versions); i++ {
+				report, err := validator.ValidateVersions(crd, crd.Versions[i-1].Name, crd.Versions[i].Name)
+				if err != nil {
+					return fmt.Errorf("failed to validate %s: %w", crd.Kind, err)
+				}
+
+				reports = append(reports, report)
+			}
+
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(reports)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+)
+
+var (
+	// cacheCmd is root for the commands that manage the cache of remote sources.
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of git repositories and URLs.",
+	}
+
+	// cachePruneCmd removes cache entries.
+	cachePruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove cached git repositories and URLs that weren't used recently.",
+		RunE:  runCachePrune,
+	}
+)
+
+type cacheCmdArgs struct {
+	maxAge time.Duration
+}
+
+var cacheArgs = &cacheCmdArgs{}
+
+const defaultMaxAge = 30 * 24 * time.Hour
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	cacheCmd.PersistentFlags().StringVar(&args.cacheDir, "cache-dir", cache.DefaultDir(), "The folder in which git repositories and URLs are cached.")
+	cachePruneCmd.Flags().DurationVar(&cacheArgs.maxAge, "max-age", defaultMaxAge, "Remove entries that weren't used for longer than this. 0 removes every entry.")
+}
+
+func runCachePrune(cmd *cobra.Command, _ []string) error {
+	c, err := cache.New(args.cacheDir, false, false)
+	if err != nil {
+		return err
+	}
+
+	removed, err := c.Prune(cacheArgs.maxAge)
+	for _, entry := range removed {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "removed", entry)
+	}
+
+	return err
+}
+
+// newCache returns the cache of remote sources configured by the flags. Without a cache folder
+// remote sources are fetched every time.
+func newCache(args *rootArgs) (*cache.Cache, error) {
+	if args.cacheDir == "" {
+		if args.offline {
+			return nil, errors.New("offline mode needs a cache folder, set one with --cache-dir")
+		}
+
+		return nil, nil
+	}
+
+	return cache.New(args.cacheDir, args.offline, args.refresh)
+}
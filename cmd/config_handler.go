@@ -1,100 +1,325 @@
 package cmd
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
-	"path/filepath"
-
-	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/build"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/config"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/kube"
 )
 
 // ConfigHandler contains config.
 type ConfigHandler struct {
 	configFileLocation string
+	cache              *cache.Cache
+	client             *http.Client
+	http               fetcher.ClientOptions
+	// providers are used by sources without credentials settings.
+	providers []credentials.Provider
+	// config is the loaded config file.
+	config *config.RenderConfig
+	// parallel is the number of sources that are loaded at once.
+	parallel int
+}
+
+// source is a handler of a group and a description of it for errors.
+type source struct {
+	name    string
+	handler Handler
 }
 
-// CRDs returns schema types gathered from a config.
+// CRDs returns schema types gathered from a config. The sources of every group are loaded concurrently
+// and the error of every source is returned.
 func (h *ConfigHandler) CRDs() ([]*pkg.SchemaType, error) {
+	configFile, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+
+	type groupSource struct {
+		group int
+		source
+	}
+
+	var (
+		sources []groupSource
+		errs    []error
+	)
+
+	for i, group := range configFile.APIGroups {
+		groupSources, err := h.sources(group)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		for _, s := range groupSources {
+			sources = append(sources, groupSource{group: i, source: s})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	results := make([][]*pkg.SchemaType, len(sources))
+
+	errs = runParallel(h.parallel, len(sources), func(i int) error {
+		s := sources[i]
+
+		crds, err := s.handler.CRDs()
+		if err != nil {
+			return fmt.Errorf("failed to process CRDs for %s in group %s: %w", s.name, configFile.APIGroups[s.group].Name, err)
+		}
+
+		results[i] = crds
+
+		return nil
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	// the CRDs of a group are gathered in the order of its sources.
+	groups := make([][]*pkg.SchemaType, len(configFile.APIGroups))
+	for i, s := range sources {
+		groups[s.group] = append(groups[s.group], results[i]...)
+	}
+
+	var result []*pkg.SchemaType
+
+	for i, group := range configFile.APIGroups {
+		result = append(result, applyGroup(group, groups[i])...)
+	}
+
+	pkg.SortSchemaTypes(result)
+
+	return result, nil
+}
+
+// load reads and validates the config file once.
+func (h *ConfigHandler) load() (*config.RenderConfig, error) {
+	if h.config != nil {
+		return h.config, nil
+	}
+
 	if _, err := os.Stat(h.configFileLocation); os.IsNotExist(err) {
 		return nil, fmt.Errorf("file under '%s' does not exist", h.configFileLocation)
 	}
 
-	content, err := os.ReadFile(filepath.Clean(h.configFileLocation))
+	configFile, err := config.Load(h.configFileLocation)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, err
 	}
 
-	configFile := &RenderConfig{}
-	if err = yaml.Unmarshal(content, configFile); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config file: %w", err)
+	h.config = configFile
+
+	return configFile, nil
+}
+
+// applyGroup applies the options of the group to its CRDs.
+func applyGroup(group config.APIGroups, crds []*pkg.SchemaType) []*pkg.SchemaType {
+	crds = pkg.FilterVersions(crds, group.Versions)
+
+	for _, crd := range crds {
+		crd.Rendering = pkg.Rendering{
+			Group:       cmp.Or(group.Name, crd.Rendering.Group),
+			Description: group.Description,
+			Minimal:     group.Minimal,
+			Comments:    group.Comments,
+		}
 	}
 
-	// for each api group, call file handler and folder handler and gather all
-	// the CRDs.
-	var result []*pkg.SchemaType
+	return crds
+}
+
+// sources returns a handler for every source of the group.
+//
+//nolint:funlen // a case per source type
+func (h *ConfigHandler) sources(group config.APIGroups) ([]source, error) {
+	groupFilter, err := filter.New(group.Include, group.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct file filter for group %s: %w", group.Name, err)
+	}
 
-	for _, group := range configFile.APIGroups {
-		for _, file := range group.Files {
-			handler := FileHandler{location: file, group: group.Name}
+	// sourceFilter returns the filter of the source's own patterns, or the filter of the group.
+	sourceFilter := func(include, exclude []string) (*filter.Filter, error) {
+		if len(include) == 0 && len(exclude) == 0 {
+			return groupFilter, nil
+		}
 
-			fileResults, err := handler.CRDs()
-			if err != nil {
-				return nil, fmt.Errorf("failed to process CRDs for files in groups %s: %w", group.Name, err)
-			}
+		return filter.New(include, exclude)
+	}
+
+	var sources []source
+
+	for _, file := range group.Files {
+		sources = append(sources, source{
+			name:    "file " + file,
+			handler: &FileHandler{location: file, group: group.Name},
+		})
+	}
+
+	// the sources are already loaded in parallel, so the files of a folder are parsed one at a time to
+	// keep the number of parsers at h.parallel.
+	for _, folder := range group.Folders {
+		sources = append(sources, source{
+			name:    "folder " + folder,
+			handler: &FolderHandler{location: folder, group: group.Name, filter: groupFilter, parallel: 1},
+		})
+	}
 
-			result = append(result, fileResults...)
+	for _, url := range group.URLs {
+		name := "url " + credentials.RedactURL(url.URL)
+
+		providers, err := configProviders(url.Credentials, h.providers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials for %s: %w", name, err)
 		}
 
-		for _, folder := range group.Folders {
-			handler := FolderHandler{location: folder, group: group.Name}
+		sources = append(sources, source{name: name, handler: &URLHandler{
+			url:       url.URL,
+			username:  url.Username,
+			password:  url.Password,
+			token:     url.Token,
+			group:     group.Name,
+			cache:     h.cache,
+			client:    h.client,
+			providers: providers,
+		}})
+	}
 
-			folderResults, err := handler.CRDs()
-			if err != nil {
-				return nil, fmt.Errorf("failed to process CRDs for folders %s: %w", handler.location, err)
-			}
+	for _, url := range group.GitURLs {
+		name := "git url " + credentials.RedactURL(url.URL)
 
-			result = append(result, folderResults...)
+		providers, err := configProviders(url.Credentials, h.providers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials for %s: %w", name, err)
 		}
 
-		for _, url := range group.URLs {
-			handler := URLHandler{
-				url:      url.URL,
-				username: url.Username,
-				password: url.Password,
-				token:    url.Token,
-				group:    group.Name,
-			}
-
-			crds, err := handler.CRDs()
-			if err != nil {
-				return nil, fmt.Errorf("failed to process CRDs for url %s: %w", handler.url, err)
-			}
-
-			result = append(result, crds...)
+		sources = append(sources, source{name: name, handler: &GitHandler{
+			URL:         url.URL,
+			Username:    url.Username,
+			Password:    url.Password,
+			Token:       url.Token,
+			Ref:         cmp.Or(url.Ref, url.Tag),
+			Paths:       url.Paths,
+			privSSHKey:  url.PrivateKey,
+			useSSHAgent: url.UseSSHAgent,
+			group:       group.Name,
+			filter:      groupFilter,
+			cache:       h.cache,
+			http:        h.http,
+			providers:   providers,
+		}})
+	}
+
+	for _, a := range group.Archives {
+		name := "archive " + credentials.RedactURL(a.Location)
+
+		archiveFilter, err := sourceFilter(a.Include, a.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct file filter for %s: %w", name, err)
 		}
 
-		for _, url := range group.GitURLs {
-			handler := GitHandler{
-				URL:         url.URL,
-				Username:    url.Username,
-				Password:    url.Password,
-				Token:       url.Token,
-				Tag:         url.Tag,
-				privSSHKey:  url.PrivateKey,
-				useSSHAgent: url.UseSSHAgent,
-				group:       group.Name,
-			}
-
-			crds, err := handler.CRDs()
-			if err != nil {
-				return nil, fmt.Errorf("failed to process CRDs for git url %s: %w", handler.URL, err)
-			}
-
-			result = append(result, crds...)
+		providers, err := configProviders(a.Credentials, h.providers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials for %s: %w", name, err)
 		}
+
+		sources = append(sources, source{name: name, handler: &ArchiveHandler{
+			location:  a.Location,
+			username:  a.Username,
+			password:  a.Password,
+			token:     a.Token,
+			group:     group.Name,
+			filter:    archiveFilter,
+			cache:     h.cache,
+			client:    h.client,
+			providers: providers,
+		}})
 	}
 
-	return result, nil
+	for _, k := range group.Kube {
+		sources = append(sources, source{name: "cluster " + cmp.Or(k.Context, "of the current context"), handler: &KubeHandler{
+			selector: kube.Selector{
+				Name:          k.Name,
+				Group:         k.Group,
+				LabelSelector: k.Selector,
+			},
+			kubeconfig: k.Kubeconfig,
+			context:    k.Context,
+			group:      group.Name,
+			// CRDs are always discovered as apiextensions.k8s.io/v1 customresourcedefinitions.
+			resourceGroup:   "apiextensions.k8s.io",
+			resourceVersion: "v1",
+			resource:        "customresourcedefinitions",
+		}})
+	}
+
+	if group.Stdin {
+		sources = append(sources, source{name: "stdin", handler: &StdInHandler{group: group.Name}})
+	}
+
+	for _, chart := range group.Helm {
+		sources = append(sources, source{
+			name:    "helm chart " + chart.Chart,
+			handler: &HelmHandler{chart: chart.Chart, values: chart.Values, group: group.Name},
+		})
+	}
+
+	for _, artifact := range group.OCI {
+		name := "oci artifact " + artifact.Ref
+
+		ociFilter, err := sourceFilter(artifact.Include, artifact.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct file filter for %s: %w", name, err)
+		}
+
+		sources = append(sources, source{
+			name:    name,
+			handler: &OCIHandler{ref: artifact.Ref, group: group.Name, filter: ociFilter},
+		})
+	}
+
+	return sources, nil
+}
+
+// configOutputs returns the outputs of the config file if the CRDs come from one and neither the
+// output nor the stdout flag is set.
+func configOutputs(crdHandler Handler) ([]config.Output, error) {
+	h, ok := crdHandler.(*ConfigHandler)
+	if !ok || crdArgs.output != "" || crdArgs.stdOut {
+		return nil, nil
+	}
+
+	configFile, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return configFile.Outputs, nil
+}
+
+// renderConfigOutputs loads the CRDs once and renders every output of the config file.
+func renderConfigOutputs(crdHandler Handler, outputs []config.Output) error {
+	crds, err := loadCRDs(crdHandler)
+	if err != nil {
+		return err
+	}
+
+	// without a previous build every file is rendered.
+	b := build.New(nil, Version, true)
+
+	return buildOutputs(b, crds, outputs)
 }
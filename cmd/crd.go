@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +11,11 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/kube"
 )
 
 const (
@@ -17,6 +23,8 @@ const (
 	FormatHTML = "html"
 	// FormatYAML is a setting that is accepted as a format output type. The type is YAML.
 	FormatYAML = "yaml"
+	// FormatMarkdown is a setting that is accepted as a format output type. The type is Markdown.
+	FormatMarkdown = "markdown"
 )
 
 // crdCmd is the command that generates CRD output.
@@ -34,6 +42,8 @@ type crdGenArgs struct {
 	format     string
 	stdOut     bool
 	cssFile    string
+	diagram    bool
+	depth      int
 }
 
 var crdArgs = &crdGenArgs{}
@@ -50,9 +60,11 @@ func init() {
 	f.BoolVarP(&crdArgs.minimal, "minimal", "l", false, "If set, only the minimal required example yaml is generated.")
 	f.BoolVar(&crdArgs.skipRandom, "no-random", false, "Skip generating random values that satisfy the property patterns.")
 	f.StringVarP(&crdArgs.output, "output", "o", "", "The location of the output file. Default is next to the CRD.")
-	f.StringVarP(&crdArgs.format, "format", "f", FormatYAML, "The format in which to output. Default is YAML. Options are: yaml, html.")
+	f.StringVarP(&crdArgs.format, "format", "f", FormatYAML, "The format in which to output. Default is YAML. Options are: yaml, html, markdown.")
 	f.BoolVarP(&crdArgs.stdOut, "stdout", "s", false, "If set, it will output the generated content to stdout.")
 	f.StringVar(&crdArgs.cssFile, "css-file", "", "Path to a custom CSS file to inject into HTML output. Only valid when format is html.")
+	f.BoolVar(&crdArgs.diagram, "diagram", false, "If set, a Mermaid diagram of the schema structure is embedded into html and markdown output.")
+	f.IntVar(&crdArgs.depth, "diagram-depth", 3, "The number of nested object levels to expand in embedded diagrams. 0 expands everything.")
 }
 
 func runGenerate(_ *cobra.Command, _ []string) error {
@@ -61,15 +73,21 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	if crdArgs.format == FormatHTML {
-		if crdArgs.output == "" {
-			return errors.New("output must be set to a filename if format is HTML")
-		}
+	outputs, err := configOutputs(crdHandler)
+	if err != nil {
+		return err
+	}
 
-		err := pkg.LoadTemplates()
-		if err != nil {
-			return fmt.Errorf("failed to load templates: %w", err)
-		}
+	if len(outputs) > 0 {
+		return renderConfigOutputs(crdHandler, outputs)
+	}
+
+	if crdArgs.format == FormatHTML && crdArgs.output == "" {
+		return errors.New("output must be set to a filename if format is HTML")
+	}
+
+	if crdArgs.format == FormatMarkdown && crdArgs.output == "" && !crdArgs.stdOut {
+		return errors.New("output must be set to a folder if format is markdown")
 	}
 
 	// determine location of output
@@ -82,9 +100,24 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 		crdArgs.output = filepath.Dir(loc)
 	}
 
+	crds, err := loadCRDs(crdHandler)
+	if err != nil {
+		return err
+	}
+
+	return render(crds, target{
+		format:  crdArgs.format,
+		output:  crdArgs.output,
+		stdOut:  crdArgs.stdOut,
+		cssFile: crdArgs.cssFile,
+	})
+}
+
+// loadCRDs returns the CRDs of the handler, enhanced with the conditions of the API folder if it's set.
+func loadCRDs(crdHandler Handler) ([]*pkg.SchemaType, error) {
 	crds, err := crdHandler.CRDs()
 	if err != nil {
-		return fmt.Errorf("failed to load CRDs: %w", err)
+		return nil, fmt.Errorf("failed to load CRDs: %w", err)
 	}
 
 	// Enhance CRDs with conditions from the API folder if specified
@@ -92,19 +125,39 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 		enhancer := pkg.NewConditionEnhancer(args.apiFolder)
 		err := enhancer.LoadConditions()
 		if err != nil {
-			return fmt.Errorf("failed to load conditions: %w", err)
+			return nil, fmt.Errorf("failed to load conditions: %w", err)
 		}
 
 		crds = enhancer.EnhanceSchemas(crds)
 	}
 
-	var w io.WriteCloser
+	return crds, nil
+}
+
+// target is where and in which format the CRDs are rendered.
+type target struct {
+	format  string
+	output  string
+	stdOut  bool
+	cssFile string
+}
 
-	if crdArgs.format == FormatHTML {
-		if crdArgs.stdOut {
+// render writes the CRDs in the format of the target. The other render options are taken from the flags.
+func render(crds []*pkg.SchemaType, t target) error {
+	var (
+		w   io.WriteCloser
+		err error
+	)
+
+	if t.format == FormatHTML {
+		if err := pkg.LoadTemplates(); err != nil {
+			return fmt.Errorf("failed to load templates: %w", err)
+		}
+
+		if t.stdOut {
 			w = os.Stdout
 		} else {
-			w, err = os.Create(crdArgs.output)
+			w, err = os.Create(t.output)
 			if err != nil {
 				return fmt.Errorf("failed to create output file: %w", err)
 			}
@@ -112,32 +165,45 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 
 		var customCSS string
 
-		if crdArgs.cssFile != "" {
+		if t.cssFile != "" {
 			var err error
 
-			customCSS, err = pkg.SanitizeCSS(crdArgs.cssFile)
+			customCSS, err = pkg.SanitizeCSS(t.cssFile)
 			if err != nil {
 				return fmt.Errorf("failed to process CSS file: %w", err)
 			}
 		}
 
 		opts := pkg.RenderOpts{
-			Comments:  crdArgs.comments,
-			Minimal:   crdArgs.minimal,
-			Random:    crdArgs.skipRandom,
-			CustomCSS: customCSS,
+			Comments:     crdArgs.comments,
+			Minimal:      crdArgs.minimal,
+			Random:       crdArgs.skipRandom,
+			CustomCSS:    customCSS,
+			Diagram:      crdArgs.diagram,
+			DiagramDepth: crdArgs.depth,
 		}
 
 		return pkg.RenderContent(w, crds, opts)
 	}
 
+	if t.format == FormatMarkdown {
+		return renderMarkdown(crds, t)
+	}
+
 	var errs []error //nolint:prealloc // nope
 
-	for _, crd := range crds {
-		if crdArgs.stdOut {
-			w = os.Stdout
+	for i, crd := range crds {
+		if t.stdOut {
+			// multiple CRDs are written to the same output, so it must not be closed after each of them.
+			w = nopCloser{Writer: os.Stdout}
+
+			if i > 0 {
+				if _, err := w.Write([]byte("\n---\n")); err != nil {
+					return fmt.Errorf("failed to write yaml delimiter to stdout: %w", err)
+				}
+			}
 		} else {
-			outputLocation := filepath.Join(crdArgs.output, crd.Kind+"_sample."+crdArgs.format)
+			outputLocation := filepath.Join(t.output, crd.Kind+"_sample."+t.format)
 			// closed later during render
 			outputFile, err := os.Create(filepath.Clean(outputLocation))
 			if err != nil {
@@ -149,19 +215,114 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 			w = outputFile
 		}
 
-		errs = append(errs, pkg.Generate(crd, w, crdArgs.comments, crdArgs.minimal, crdArgs.skipRandom))
+		opts := crd.Rendering.Options(pkg.RenderOpts{Comments: crdArgs.comments, Minimal: crdArgs.minimal})
+		errs = append(errs, pkg.Generate(crd, w, opts.Comments, opts.Minimal, crdArgs.skipRandom))
 	}
 
 	return errors.Join(errs...)
 }
 
+// nopCloser wraps a writer that must stay open after rendering.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// renderMarkdown writes a reference page per CRD and a navigation index into the output folder.
+func renderMarkdown(crds []*pkg.SchemaType, t target) error {
+	opts := pkg.RenderOpts{
+		Comments:     crdArgs.comments,
+		Minimal:      crdArgs.minimal,
+		Random:       crdArgs.skipRandom,
+		Diagram:      crdArgs.diagram,
+		DiagramDepth: crdArgs.depth,
+	}
+
+	if t.stdOut {
+		var errs []error
+		for _, crd := range crds {
+			errs = append(errs, pkg.RenderMarkdown(os.Stdout, crd, crds, opts))
+		}
+
+		return errors.Join(errs...)
+	}
+
+	var errs []error
+
+	for _, crd := range crds {
+		location := filepath.Join(t.output, filepath.FromSlash(pkg.MarkdownPagePath(crd)))
+		errs = append(errs, writeFile(location, func(w io.Writer) error {
+			return pkg.RenderMarkdown(w, crd, crds, opts)
+		}))
+	}
+
+	errs = append(errs, writeFile(filepath.Join(t.output, pkg.MarkdownIndex), func(w io.Writer) error {
+		return pkg.RenderMarkdownIndex(w, crds)
+	}))
+
+	return errors.Join(errs...)
+}
+
+// writeFile creates the file and any missing parent folders and calls render with it.
+func writeFile(location string, render func(io.Writer) error) (err error) {
+	const perm = 0o755
+	if err := os.MkdirAll(filepath.Dir(location), perm); err != nil {
+		return fmt.Errorf("failed to create folder for '%s': %w", location, err)
+	}
+
+	file, err := os.Create(filepath.Clean(location))
+	if err != nil {
+		return fmt.Errorf("failed to create file at: '%s': %w", location, err)
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+
+	return render(file)
+}
+
 func constructHandler(args *rootArgs) (Handler, error) {
 	var crdHandler Handler
 
+	fileFilter, err := filter.New(args.include, args.exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct file filter: %w", err)
+	}
+
+	remoteCache, err := newCache(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct cache: %w", err)
+	}
+
+	httpOpts, err := newHTTPOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := fetcher.NewClient(httpOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct http client: %w", err)
+	}
+
+	providers, err := newCredentialProviders(args)
+	if err != nil {
+		return nil, err
+	}
+
 	switch {
-	case args.kubeCluster != "":
+	case args.kubeCluster != "" || args.kubeGroup != "" || args.kubeSelector != "":
 		crdHandler = &KubeHandler{
-			crd:             args.kubeCluster,
+			selector: kube.Selector{
+				Name:          args.kubeCluster,
+				Group:         args.kubeGroup,
+				LabelSelector: args.kubeSelector,
+			},
+			kubeconfig:      args.kubeconfig,
+			context:         args.kubeContext,
 			resourceGroup:   args.group,
 			resourceVersion: args.version,
 			resource:        args.resource,
@@ -171,32 +332,96 @@ func constructHandler(args *rootArgs) (Handler, error) {
 	case args.fileLocation != "":
 		crdHandler = &FileHandler{location: args.fileLocation}
 	case args.folderLocation != "":
-		crdHandler = &FolderHandler{location: args.folderLocation}
+		crdHandler = &FolderHandler{location: args.folderLocation, filter: fileFilter, parallel: args.parallel}
+	case args.helmChart != "":
+		crdHandler = &HelmHandler{chart: args.helmChart, values: args.helmValues}
+	case args.kustomize != "":
+		crdHandler = &KustomizeHandler{location: args.kustomize}
+	case args.goTypes != "":
+		crdHandler = &GoTypesHandler{location: args.goTypes}
+	case args.archive != "":
+		crdHandler = &ArchiveHandler{
+			location:  args.archive,
+			username:  args.username,
+			password:  args.password,
+			token:     args.token,
+			filter:    fileFilter,
+			cache:     remoteCache,
+			client:    httpClient,
+			providers: providers,
+		}
+	case args.oci != "":
+		crdHandler = &OCIHandler{ref: args.oci, filter: fileFilter}
+	case args.openAPI != "":
+		crdHandler = &OpenAPIHandler{
+			location:  args.openAPI,
+			username:  args.username,
+			password:  args.password,
+			token:     args.token,
+			cache:     remoteCache,
+			client:    httpClient,
+			providers: providers,
+		}
+	case len(args.jsonSchemas) > 0:
+		crdHandler = &JSONSchemaHandler{
+			locations: args.jsonSchemas,
+			versions:  args.jsonSchemaVersions,
+			kind:      args.jsonSchemaKind,
+			apiGroup:  args.jsonSchemaGroup,
+		}
+	case args.olmBundle != "":
+		crdHandler = &OLMBundleHandler{location: args.olmBundle}
 	case args.configFileLocation != "":
-		crdHandler = &ConfigHandler{configFileLocation: args.configFileLocation}
-	case args.gitURL != "":
-		crdHandler = &GitHandler{
-			URL:         args.gitURL,
-			Username:    args.username,
-			Password:    args.password,
-			Token:       args.token,
-			Tag:         args.tag,
-			caBundle:    args.caBundle,
-			privSSHKey:  args.privSSHKey,
-			useSSHAgent: args.useSSHAgent,
+		crdHandler = &ConfigHandler{
+			configFileLocation: args.configFileLocation,
+			cache:              remoteCache,
+			client:             httpClient,
+			http:               httpOpts,
+			providers:          providers,
+			parallel:           args.parallel,
 		}
+	case args.gitURL != "" || args.gitRepo != "":
+		crdHandler = newGitHandler(args, fileFilter, remoteCache, httpOpts, providers)
 	case args.url != "":
 		crdHandler = &URLHandler{
-			url:      args.url,
-			username: args.username,
-			password: args.password,
-			token:    args.token,
+			url:       args.url,
+			username:  args.username,
+			password:  args.password,
+			token:     args.token,
+			cache:     remoteCache,
+			client:    httpClient,
+			providers: providers,
 		}
 	}
 
 	if crdHandler == nil {
-		return nil, errors.New("one of the flags (file, folder, url, configFile) must be set")
+		return nil, errors.New("one of the flags (file, folder, url, configFile, helm-chart, kustomize, go-types, archive, oci, openapi, json-schema, olm-bundle) must be set")
 	}
 
 	return crdHandler, nil
 }
+
+func newGitHandler(
+	args *rootArgs,
+	fileFilter *filter.Filter,
+	remoteCache *cache.Cache,
+	httpOpts fetcher.ClientOptions,
+	providers []credentials.Provider,
+) *GitHandler {
+	return &GitHandler{
+		URL:         args.gitURL,
+		Repo:        args.gitRepo,
+		Worktree:    args.gitWorktree,
+		Username:    args.username,
+		Password:    args.password,
+		Token:       args.token,
+		Ref:         cmp.Or(args.gitRef, args.tag),
+		Paths:       args.gitPaths,
+		http:        httpOpts,
+		privSSHKey:  args.privSSHKey,
+		useSSHAgent: args.useSSHAgent,
+		filter:      fileFilter,
+		cache:       remoteCache,
+		providers:   providers,
+	}
+}
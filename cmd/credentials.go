@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/config"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+)
+
+// newCredentialProviders returns the providers of the credential-exec and credential-helper flags.
+func newCredentialProviders(args *rootArgs) ([]credentials.Provider, error) {
+	var exec *config.ExecCredentials
+
+	if fields := strings.Fields(args.credentialExec); len(fields) > 0 {
+		exec = &config.ExecCredentials{Command: fields[0], Args: fields[1:]}
+	}
+
+	return newProviders(args.credentialHelpers, exec)
+}
+
+// configProviders returns the providers of a config file source. Sources without credentials use the
+// providers of the flags.
+func configProviders(c *config.Credentials, defaults []credentials.Provider) ([]credentials.Provider, error) {
+	if c == nil {
+		return defaults, nil
+	}
+
+	return newProviders(c.Helpers, c.Exec)
+}
+
+func newProviders(helpers []string, exec *config.ExecCredentials) ([]credentials.Provider, error) {
+	providers, err := credentials.NewProviders(helpers)
+	if err != nil {
+		return nil, err
+	}
+
+	if exec != nil {
+		providers = append([]credentials.Provider{&credentials.Exec{Command: exec.Command, Args: exec.Args}}, providers...)
+	}
+
+	return providers, nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+const (
+	// FormatCUE is the extension and name of the CUE definition output.
+	FormatCUE = "cue"
+	// FormatPkl is the extension and name of the Pkl definition output.
+	FormatPkl = "pkl"
+)
+
+var (
+	// cueCmd is a command that generates CUE definitions.
+	cueCmd = &cobra.Command{
+		Use:   "cue",
+		Short: "Generate CUE definitions from the CRD.",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runGenerateDefinition(FormatCUE, pkg.RenderCUE)
+		},
+	}
+
+	// pklCmd is a command that generates Pkl modules.
+	pklCmd = &cobra.Command{
+		Use:   "pkl",
+		Short: "Generate Pkl modules from the CRD.",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runGenerateDefinition(FormatPkl, pkg.RenderPkl)
+		},
+	}
+)
+
+type definitionCmdArgs struct {
+	outputFolder string
+	stdOut       bool
+}
+
+var definitionArgs = &definitionCmdArgs{}
+
+func init() {
+	for _, c := range []*cobra.Command{cueCmd, pklCmd} {
+		generateCmd.AddCommand(c)
+		f := c.PersistentFlags()
+		f.StringVarP(&definitionArgs.outputFolder, "output", "o", ".", "output location of the generated definition files")
+		f.BoolVarP(&definitionArgs.stdOut, "stdout", "s", false, "If set, it will output the generated content to stdout.")
+	}
+}
+
+func runGenerateDefinition(format string, render func(io.Writer, *pkg.Definition) error) error {
+	crdHandler, err := constructHandler(args)
+	if err != nil {
+		return err
+	}
+
+	crds, err := crdHandler.CRDs()
+	if err != nil {
+		return fmt.Errorf("failed to load CRDs: %w", err)
+	}
+
+	var errs []error
+
+	for _, crd := range crds {
+		for _, def := range pkg.NewDefinitions(crd) {
+			if definitionArgs.stdOut {
+				errs = append(errs, render(os.Stdout, def))
+
+				continue
+			}
+
+			location := filepath.Join(definitionArgs.outputFolder, def.Kind+"."+def.Group+"."+def.Version+"."+format)
+
+			errs = append(errs, writeFile(location, func(w io.Writer) error {
+				return render(w, def)
+			}))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+// diagramCmd is a command that renders the structure of CRD schemas as a diagram.
+var diagramCmd = &cobra.Command{
+	Use:   "diagram",
+	Short: "Generate a Mermaid or Graphviz diagram of the schema structure.",
+	RunE:  runGenerateDiagram,
+}
+
+type diagramCmdArgs struct {
+	format        string
+	depth         int
+	schemaVersion string
+	output        string
+}
+
+var diagramArgs = &diagramCmdArgs{}
+
+func init() {
+	generateCmd.AddCommand(diagramCmd)
+	f := diagramCmd.PersistentFlags()
+	f.StringVarP(&diagramArgs.format, "format", "f", pkg.DiagramMermaid, "The format of the diagram. Options are: mermaid, dot.")
+	f.IntVar(&diagramArgs.depth, "depth", 3, "The number of nested object levels to expand. 0 expands everything.")
+	f.StringVar(&diagramArgs.schemaVersion, "schema-version", "", "The CRD version to render. Defaults to the last version of each CRD.")
+	f.StringVarP(&diagramArgs.output, "output", "o", "", "The location of the output file. Default is stdout.")
+}
+
+func runGenerateDiagram(_ *cobra.Command, _ []string) error {
+	crdHandler, err := constructHandler(args)
+	if err != nil {
+		return err
+	}
+
+	crds, err := crdHandler.CRDs()
+	if err != nil {
+		return fmt.Errorf("failed to load CRDs: %w", err)
+	}
+
+	opts := pkg.DiagramOpts{
+		Format: diagramArgs.format,
+		Depth:  diagramArgs.depth,
+	}
+
+	if diagramArgs.output == "" {
+		return pkg.RenderDiagram(os.Stdout, crds, diagramArgs.schemaVersion, opts)
+	}
+
+	return writeFile(diagramArgs.output, func(w io.Writer) error {
+		return pkg.RenderDiagram(w, crds, diagramArgs.schemaVersion, opts)
+	})
+}
@@ -1,15 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
-
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
-	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
 )
 
 // FileHandler provides options for a file provider.
@@ -29,28 +27,36 @@ func (h *FileHandler) CRDs() ([]*pkg.SchemaType, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	content, err = sanitize.Sanitize(content)
+	schemaTypes, err := pkg.DecodeSchemaTypes(content, h.location, os.Stderr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sanitize content: %w", err)
+		return nil, fmt.Errorf("failed to extract schema types: %w", err)
 	}
 
-	crd := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal(content, crd); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal into custom resource definition: %w", err)
-	}
+	setGroup(schemaTypes, h.group)
 
-	schemaType, err := pkg.ExtractSchemaType(crd)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract schema type: %w", err)
+	return schemaTypes, nil
+}
+
+// setGroup sets the rendering group of the schema types if a group is configured.
+func setGroup(schemaTypes []*pkg.SchemaType, group string) {
+	if group == "" {
+		return
 	}
 
-	if schemaType == nil {
-		return nil, nil
+	for _, schemaType := range schemaTypes {
+		schemaType.Rendering = pkg.Rendering{Group: group}
 	}
+}
 
-	if h.group != "" {
-		schemaType.Rendering = pkg.Rendering{Group: h.group}
+// skipUndecodable notes files that don't decode as YAML or JSON, which sources that search for CRDs
+// skip, and returns every other error, like the one of a CRD that fails to be extracted.
+func skipUndecodable(log io.Writer, name string, err error) error {
+	var decodeErr *pkg.DecodeError
+	if !errors.As(err, &decodeErr) {
+		return err
 	}
 
-	return []*pkg.SchemaType{schemaType}, nil
+	_, _ = fmt.Fprintf(log, "skipping file %s: %s\n", name, err)
+
+	return nil
 }
@@ -1,22 +1,24 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
-
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
-	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
 )
 
 // FolderHandler scans folders and returns schemas found in that folder.
 type FolderHandler struct {
 	location string
 	group    string
+	filter   *filter.Filter
+	// parallel is the number of files that are parsed at once.
+	parallel int
 }
 
 // CRDs goes through schemas in folders.
@@ -33,7 +35,8 @@ func (h *FolderHandler) CRDs() ([]*pkg.SchemaType, error) {
 		_ = dir.Close()
 	}()
 
-	var crds []*pkg.SchemaType
+	// the files are gathered first and parsed concurrently afterwards.
+	var files []string
 
 	err = filepath.Walk(h.location, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
@@ -49,46 +52,62 @@ func (h *FolderHandler) CRDs() ([]*pkg.SchemaType, error) {
 			return fmt.Errorf("failed to get relative path for %s: %w", path, err)
 		}
 
-		if filepath.Ext(rel) != ".yaml" {
+		if !h.filter.Match(rel) {
+			return nil
+		}
+
+		if !filter.Candidate(rel) {
 			_, _ = fmt.Fprintln(os.Stderr, "skipping file "+rel)
 
 			return nil
 		}
 
+		files = append(files, rel)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk the selected folder: %w", err)
+	}
+
+	results := make([][]*pkg.SchemaType, len(files))
+
+	errs := runParallel(h.parallel, len(files), func(i int) error {
+		rel := files[i]
+
 		content, err := dir.ReadFile(rel)
 		if err != nil {
 			return fmt.Errorf("failed to read file: %w", err)
 		}
 
-		content, err = sanitize.Sanitize(content)
-		if err != nil {
-			return fmt.Errorf("failed to sanitize content: %w", err)
+		// files without an extension are only kept if their content is a CRD, so they are checked quietly.
+		log := io.Writer(os.Stderr)
+		if !filter.HasExtension(rel) {
+			log = io.Discard
 		}
 
-		crd := &unstructured.Unstructured{}
-		if err := yaml.Unmarshal(content, crd); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "skipping none CRD file: "+path)
+		path := filepath.Join(h.location, rel)
 
-			return nil //nolint:nilerr // intentional none nil
-		}
-		schemaType, err := pkg.ExtractSchemaType(crd)
+		schemaTypes, err := pkg.DecodeSchemaTypes(content, path, log)
 		if err != nil {
-			return fmt.Errorf("failed to extract schema type: %w", err)
+			return skipUndecodable(log, path, err)
 		}
 
-		if schemaType != nil {
-			if h.group != "" {
-				schemaType.Rendering = pkg.Rendering{Group: h.group}
-			}
-
-			crds = append(crds, schemaType)
-		}
+		setGroup(schemaTypes, h.group)
+		results[i] = schemaTypes
 
 		return nil
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to walk the selected folder: %w", err)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var crds []*pkg.SchemaType
+	for _, schemaTypes := range results {
+		crds = append(crds, schemaTypes...)
 	}
 
+	pkg.SortSchemaTypes(crds)
+
 	return crds, nil
 }
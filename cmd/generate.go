@@ -1,7 +1,13 @@
 package cmd
 
 import (
+	"runtime"
+	"time"
+
 	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
 )
 
 type rootArgs struct {
@@ -12,17 +18,50 @@ type rootArgs struct {
 	username           string
 	password           string
 	token              string
+	credentialHelpers  []string
+	credentialExec     string
 	tag                string
+	gitRef             string
+	gitPaths           []string
 	caBundle           string
+	clientCert         string
+	clientKey          string
+	proxy              string
+	httpTimeout        time.Duration
+	httpRetries        int
 	privSSHKey         string
 	gitURL             string
+	gitRepo            string
+	gitWorktree        bool
 	kubeCluster        string
+	kubeGroup          string
+	kubeSelector       string
+	kubeconfig         string
+	kubeContext        string
 	group              string
 	version            string
 	resource           string
 	useSSHAgent        bool
 	stdin              bool
 	apiFolder          string
+	include            []string
+	exclude            []string
+	helmChart          string
+	helmValues         []string
+	kustomize          string
+	goTypes            string
+	archive            string
+	oci                string
+	openAPI            string
+	jsonSchemas        []string
+	jsonSchemaKind     string
+	jsonSchemaVersions []string
+	jsonSchemaGroup    string
+	olmBundle          string
+	cacheDir           string
+	offline            bool
+	refresh            bool
+	parallel           int
 }
 
 var (
@@ -42,19 +81,56 @@ func init() {
 	f.StringVar(&args.apiFolder, "api", "", "Path to folder containing Go API types with condition annotations (enhances any input type).")
 	f.BoolVarP(&args.stdin, "stdin", "i", false, "Take CRD content from stdin.")
 	f.StringVarP(&args.fileLocation, "crd", "c", "", "The CRD file to generate a yaml from.")
-	f.StringVarP(&args.kubeCluster, "kube", "k", "", "Try to access the local cluster and fetch CRD content from there.")
+	f.StringVarP(&args.kubeCluster, "kube", "k", "", "Try to access the cluster and fetch CRD content from there. Accepts a CRD name or a glob, like '*' for all CRDs.")
+	f.StringVar(&args.kubeGroup, "kube-group", "", "Only fetch CRDs of this API group from the cluster, like monitoring.coreos.com.")
+	f.StringVar(&args.kubeSelector, "kube-selector", "", "Only fetch CRDs matching this label selector from the cluster.")
+	f.StringVar(&args.kubeconfig, "kubeconfig", "", "The kubeconfig used to access the cluster. Defaults to KUBECONFIG, ~/.kube/config or the in-cluster config.")
+	f.StringVar(&args.kubeContext, "context", "", "The kubeconfig context used to access the cluster. Defaults to the current context.")
 	f.StringVarP(&args.folderLocation, "folder", "r", "", "A folder from which to parse a series of CRDs.")
 	f.StringVarP(&args.url, "url", "u", "", "If provided, will use this URL to fetch CRD YAML content from.")
+	f.StringVar(&args.helmChart, "helm-chart", "", "A Helm chart folder or packaged archive that is rendered offline to discover CRDs.")
+	f.StringSliceVar(&args.helmValues, "helm-values", nil, "Values files used to render the Helm chart. Chart defaults are used if not set.")
+	f.StringVar(&args.kustomize, "kustomize", "", "A folder with a kustomization that is built to discover CRDs.")
+	f.StringVar(&args.goTypes, "go-types", "", "A folder with kubebuilder annotated Go API types to build CRDs from, without running controller-gen.")
+	f.StringVar(&args.archive, "archive", "", "A tar, tar.gz or zip archive, as a path or URL, from which to parse a series of CRDs.")
+	f.StringVar(&args.oci, "oci", "", "An OCI artifact reference, or a local OCI image layout folder, from which to extract CRDs. Credentials are read from the docker config.")
+	f.StringVar(&args.openAPI, "openapi", "", "An OpenAPI v3 document, as a path or URL, like the ones served under /openapi/v3/apis/<group>/<version>, to generate samples for built-in kinds.")
+	f.StringSliceVar(&args.jsonSchemas, "json-schema", nil, "Plain JSON Schema documents (draft-07 or 2020-12) to wrap into a kind. Every document is a version of the kind.")
+	f.StringVar(&args.jsonSchemaKind, "json-schema-kind", "", "The kind of the JSON Schema documents.")
+	f.StringSliceVar(&args.jsonSchemaVersions, "json-schema-version", nil, "The version of each JSON Schema document, in the same order. Defaults to v1 for a single document.")
+	f.StringVar(&args.jsonSchemaGroup, "json-schema-group", "", "The optional group of the JSON Schema documents.")
+	f.StringVar(&args.olmBundle, "olm-bundle", "", "An OLM bundle folder whose CRDs are loaded. The alm-examples of its ClusterServiceVersion are validated against them.")
 	f.StringVarP(&args.gitURL, "git-url", "g", "", "If provided, CRDs will be discovered using a git repository.")
-	f.StringVar(&args.username, "username", "", "Optional username to authenticate a URL.")
-	f.StringVar(&args.password, "password", "", "Optional password to authenticate a URL.")
-	f.StringVar(&args.token, "token", "", "A bearer token to authenticate a URL.")
+	f.StringVar(&args.username, "username", "", "Optional username to authenticate a URL. ${ENV} references are expanded.")
+	f.StringVar(&args.password, "password", "", "Optional password to authenticate a URL. ${ENV} references are expanded.")
+	f.StringVar(&args.token, "token", "", "A bearer token to authenticate a URL. ${ENV} references are expanded.")
+	f.StringSliceVar(&args.credentialHelpers, "credential-helper", nil, "Where credentials are looked up, in order, if username, password and token are empty. Options are: netrc, docker, git.")
+	f.StringVar(&args.credentialExec, "credential-exec", "", "A command that prints the credentials of the URL in $CTY_CREDENTIALS_URL as JSON. It's asked before the credential helpers.")
 	f.StringVar(&args.configFileLocation, "config", "", "An optional configuration file that can define grouping data for various rendered crds.")
-	f.StringVar(&args.tag, "tag", "", "The ref to check out. Default is head.")
-	f.StringVar(&args.caBundle, "ca-bundle-file", "", "Additional certificate bundle to load. Should the name of the file.")
+	f.StringVar(&args.gitRepo, "git-repo", "", "A local git repository, or a folder inside of it, to open instead of cloning --git-url.")
+	f.BoolVar(&args.gitWorktree, "git-worktree", false, "Read the working tree of the local repository, including uncommitted changes, instead of a ref.")
+	f.StringVar(&args.tag, "tag", "", "The tag to check out. Deprecated, use --git-ref instead.")
+	f.StringVar(&args.gitRef, "git-ref", "", "The branch, tag or commit SHA to check out. Default is the default branch.")
+	f.StringSliceVar(&args.gitPaths, "git-path", nil, "Glob patterns of the files to consider in the git repository, like 'config/crd/**'. Other folders are skipped.")
+	f.StringVar(&args.caBundle, "ca-bundle-file", "", "A PEM certificate bundle to trust in addition to the system certificates, for URL, archive, OpenAPI and git sources.")
+	f.StringVar(&args.clientCert, "client-cert-file", "", "A PEM client certificate for mutual TLS with URL, archive, OpenAPI and git sources. Requires --client-key-file.")
+	f.StringVar(&args.clientKey, "client-key-file", "", "The PEM key of --client-cert-file.")
+	f.StringVar(&args.proxy, "proxy", "", "The URL of the proxy for URL, archive, OpenAPI and git sources. Default is HTTPS_PROXY, HTTP_PROXY and NO_PROXY.")
+	f.DurationVar(&args.httpTimeout, "http-timeout", fetcher.DefaultTimeout, "The timeout of a single request to a URL, archive or OpenAPI source, including reading the body. 0 disables it.")
+	f.IntVar(&args.httpRetries, "http-retries", fetcher.DefaultRetries, "The number of times a request is retried after a transient connection error or a 429 or 5xx response, with exponential backoff.")
 	f.StringVar(&args.privSSHKey, "private-ssh-key-file", "", "Private key to use for cloning. Should the name of the file.")
 	f.BoolVar(&args.useSSHAgent, "ssh-agent", false, "If set, the configured SSH agent will be used to clone the repository..")
 	f.StringVar(&args.group, "group", "apiextensions.k8s.io", "If set, it will look for this group when using Kubernetes Config.")
 	f.StringVar(&args.version, "version", "v1", "If set, it will look for this version when using Kubernetes Config.")
+	f.StringSliceVar(&args.include, "include", nil, "Glob patterns of files to consider in folder, git and archive sources. Supports '**'.")
+	f.StringSliceVar(&args.exclude, "exclude", nil, "Glob patterns of files to ignore in folder, git and archive sources. Supports '**'.")
+	f.StringVar(&args.cacheDir, "cache-dir", cache.DefaultDir(), "The folder in which git repositories and URLs are cached. Caching is disabled if empty.")
+	f.BoolVar(&args.offline, "offline", false, "Only use cached git repositories and URLs, without fetching anything.")
+	f.BoolVar(&args.refresh, "refresh", false, "Ignore cached git repositories and URLs and fetch them again.")
+	f.IntVar(&args.parallel, "parallel", runtime.NumCPU(), "The number of config file sources loaded and folder files parsed concurrently.")
 	f.StringVar(&args.resource, "resource", "customresourcedefinitions", "If set, it will look for this version when using Kubernetes Config.")
+
+	// build loads its sources with the same flags. They are added here, because the init of build
+	// runs before they are defined.
+	buildCmd.PersistentFlags().AddFlagSet(f)
 }
@@ -1,23 +1,24 @@
 package cmd
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
 	"slices"
 	"strings"
 
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
-	"github.com/go-git/go-git/v5/storage/memory"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
-	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/gitsource"
 )
 
 // GitHandler contains data to parse git configuration and values.
@@ -26,76 +27,150 @@ type GitHandler struct {
 	Username string
 	Password string
 	Token    string
-	Tag      string
-
-	caBundle    string
+	// Ref is a branch, tag or commit SHA. The default branch is used if empty.
+	Ref string
+	// Paths are glob patterns of the files to consider. Folders that can't match are skipped.
+	Paths []string
+	// Repo is a local repository that is opened instead of cloning URL.
+	Repo string
+	// Worktree reads the working tree of the local repository instead of Ref.
+	Worktree bool
+
+	http        fetcher.ClientOptions
 	privSSHKey  string
 	useSSHAgent bool
 	group       string // this is used by the configfile.
+	filter      *filter.Filter
+	cache       *cache.Cache
+	providers   []credentials.Provider
+	// creds are the resolved credentials that are redacted from errors.
+	creds credentials.Credentials
 }
 
 // CRDs returns a list of crds parsed out from crds contained in a git repository.
 func (g *GitHandler) CRDs() ([]*pkg.SchemaType, error) {
-	opts, err := g.constructGitOptions()
+	var (
+		crds []*pkg.SchemaType
+		err  error
+	)
+
+	if g.Worktree {
+		if g.Ref != "" {
+			return nil, errors.New("git-worktree can't be used with git-ref")
+		}
+
+		crds, err = g.crdsForWorktree()
+	} else {
+		crds, err = g.crdsForRef(g.Ref)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := git.Clone(memory.NewStorage(), nil, opts)
+	_, _ = fmt.Fprintf(os.Stderr, "Discovered number of CRDs in %s: %d\n", g.creds.Redact(cmp.Or(g.Repo, g.URL)), len(crds))
+
+	return crds, nil
+}
+
+// crdsForRef clones the repository at ref and returns the CRDs of that commit.
+func (g *GitHandler) crdsForRef(ref string) ([]*pkg.SchemaType, error) {
+	opts, err := g.constructGitOptions()
 	if err != nil {
-		return nil, fmt.Errorf("error cloning git repository: %w", err)
+		return nil, err
 	}
 
-	var ref *plumbing.Reference
-	if g.Tag != "" {
-		ref, err = r.Tag(g.Tag)
-	} else {
-		ref, err = r.Head()
+	opts.Ref = ref
+
+	// sources of the same repository share its mirror, so they don't fetch into it at the same time.
+	if opts.Dir != "" {
+		defer g.cache.Lock(g.URL)()
 	}
 
+	_, commit, err := gitsource.Clone(*opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct reference: %w", err)
+		return nil, g.creds.RedactError(err)
 	}
 
-	crds, err := g.gatherSchemaTypesForRef(r, ref)
+	crds, err := g.gatherSchemaTypesForRef(commit)
+
+	return crds, g.creds.RedactError(err)
+}
+
+// crdsForWorktree returns the CRDs in the working tree of the local repository, including uncommitted changes.
+func (g *GitHandler) crdsForWorktree() ([]*pkg.SchemaType, error) {
+	opts, err := g.constructGitOptions()
 	if err != nil {
 		return nil, err
 	}
 
-	_, _ = fmt.Fprintln(os.Stderr, "Discovered number of CRDs: ", len(crds))
+	if opts.LocalPath() == "" {
+		return nil, errors.New("git-worktree requires a local repository, set git-repo or a file:// git-url")
+	}
+
+	r, err := gitsource.Open(*opts)
+	if err != nil {
+		return nil, g.creds.RedactError(err)
+	}
+
+	paths, err := filter.New(g.Paths, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct git path filter: %w", err)
+	}
+
+	var crds []*pkg.SchemaType
+
+	if err := gitsource.WalkWorktree(r, paths, func(name string, read func() ([]byte, error)) error {
+		schemaTypes, err := g.processEntry(name, read)
+		if err != nil {
+			return err
+		}
+
+		crds = append(crds, schemaTypes...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
 	return crds, nil
 }
 
-func (g *GitHandler) gatherSchemaTypesForRef(r *git.Repository, ref *plumbing.Reference) ([]*pkg.SchemaType, error) {
-	// Need to resolve the ref first to the right hash otherwise it's not found.
-	hash, err := r.ResolveRevision(plumbing.Revision(ref.Hash().String()))
+// tags clones the whole repository once and returns the tags that match the pattern, oldest first.
+func (g *GitHandler) tags(pattern string) ([]gitsource.Tag, error) {
+	opts, err := g.constructGitOptions()
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve revision: %w", err)
+		return nil, err
 	}
 
-	commit, err := r.CommitObject(*hash)
+	r, err := gitsource.Open(*opts)
 	if err != nil {
-		return nil, fmt.Errorf("error getting commit object: %w", err)
+		return nil, g.creds.RedactError(err)
 	}
 
-	commitTree, err := commit.Tree()
+	return gitsource.Tags(r, pattern)
+}
+
+func (g *GitHandler) gatherSchemaTypesForRef(commit *object.Commit) ([]*pkg.SchemaType, error) {
+	paths, err := filter.New(g.Paths, nil)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to construct git path filter: %w", err)
 	}
 
 	var crds []*pkg.SchemaType
 	// Tried to make this concurrent, but there was very little gain. It just takes this long to
 	// clone a large repository. It's not the processing OR the rendering that takes long.
-	if err := commitTree.Files().ForEach(func(f *object.File) error {
-		crd, err := g.processEntry(f)
+	if err := gitsource.Walk(commit, paths, func(name string, f *object.File) error {
+		schemaTypes, err := g.processEntry(name, func() ([]byte, error) {
+			content, err := f.Contents()
+
+			return []byte(content), err
+		})
 		if err != nil {
 			return err
 		}
 
-		if crd != nil {
-			crds = append(crds, crd)
-		}
+		crds = append(crds, schemaTypes...)
 
 		return nil
 	}); err != nil {
@@ -105,72 +180,73 @@ func (g *GitHandler) gatherSchemaTypesForRef(r *git.Repository, ref *plumbing.Re
 	return crds, nil
 }
 
-func (g *GitHandler) processEntry(f *object.File) (*pkg.SchemaType, error) {
-	if slices.Contains(strings.Split(f.Name, string(filepath.Separator)), "test") {
+// processEntry decodes the CRDs of a file. The content is only read if the file is a candidate.
+func (g *GitHandler) processEntry(name string, read func() ([]byte, error)) ([]*pkg.SchemaType, error) {
+	if slices.Contains(strings.Split(name, "/"), "test") {
 		return nil, nil
 	}
 
-	if ext := filepath.Ext(f.Name); ext != ".yaml" {
+	if !filter.Candidate(name) || !g.filter.Match(name) {
 		return nil, nil
 	}
 
-	content, err := f.Contents()
+	content, err := read()
 	if err != nil {
 		return nil, err
 	}
 
-	sanitized, err := sanitize.Sanitize([]byte(content))
+	schemaTypes, err := pkg.DecodeSchemaTypes(content, name, io.Discard)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sanitize content: %w", err)
-	}
-
-	crd := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal(sanitized, crd); err != nil {
 		return nil, nil //nolint:nilerr // intentional
 	}
 
-	schemaType, err := pkg.ExtractSchemaType(crd)
-	if err != nil || schemaType == nil {
-		return nil, nil //nolint:nilerr // intentional
-	}
-
-	if g.group != "" {
-		schemaType.Rendering = pkg.Rendering{Group: g.group}
-	}
+	setGroup(schemaTypes, g.group)
 
-	return schemaType, nil
+	return schemaTypes, nil
 }
 
-func (g *GitHandler) constructGitOptions() (*git.CloneOptions, error) {
-	opts := &git.CloneOptions{
-		URL:   g.URL,
-		Depth: 1,
+func (g *GitHandler) constructGitOptions() (*gitsource.Options, error) {
+	opts := &gitsource.Options{
+		URL:  g.URL,
+		Path: g.Repo,
+	}
+
+	creds, err := credentials.Resolve(g.URL, credentials.Credentials{
+		Username: g.Username,
+		Password: g.Password,
+		Token:    g.Token,
+	}, g.providers...)
+	if err != nil {
+		return nil, err
 	}
 
+	g.creds = creds
+
 	// trickle down. if ssh key is set, this will be overwritten.
-	if g.Username != "" && g.Password != "" {
+	if creds.Username != "" && creds.Password != "" {
 		opts.Auth = &http.BasicAuth{
-			Username: g.Username,
-			Password: g.Password,
+			Username: creds.Username,
+			Password: creds.Password,
 		}
 	}
 
-	if g.Token != "" {
+	if creds.Token != "" {
 		opts.Auth = &http.TokenAuth{
-			Token: g.Token,
+			Token: creds.Token,
 		}
 	}
 
-	if g.caBundle != "" {
-		opts.CABundle = []byte(g.caBundle)
-	}
+	opts.CABundle = g.http.CABundle
+	opts.ClientCert = g.http.ClientCert
+	opts.ClientKey = g.http.ClientKey
+	opts.Proxy = g.http.Proxy
 
 	if g.privSSHKey != "" {
 		if !strings.Contains(g.URL, "@") {
 			return nil, fmt.Errorf("git URL does not contain an ssh address: %s", g.URL)
 		}
 
-		keys, err := ssh.NewPublicKeysFromFile("git", g.privSSHKey, g.Password)
+		keys, err := ssh.NewPublicKeysFromFile("git", g.privSSHKey, creds.Password)
 		if err != nil {
 			return nil, err
 		}
@@ -191,5 +267,16 @@ func (g *GitHandler) constructGitOptions() (*git.CloneOptions, error) {
 		opts.Auth = authMethod
 	}
 
+	// local repositories are opened directly.
+	if g.cache != nil && opts.LocalPath() == "" {
+		dir, err := g.cache.GitDir(g.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.Dir = dir
+		opts.Offline = g.cache.Offline()
+	}
+
 	return opts, nil
 }
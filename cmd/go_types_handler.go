@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+// GoTypesHandler builds CRDs from kubebuilder annotated Go API types.
+type GoTypesHandler struct {
+	location string
+	group    string
+}
+
+// CRDs returns schemas built from the Go types. Condition annotations in the same folder are added as well.
+func (h *GoTypesHandler) CRDs() ([]*pkg.SchemaType, error) {
+	schemaTypes, err := pkg.NewGoTypesParser().ParseGoTypes(h.location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Go types: %w", err)
+	}
+
+	enhancer := pkg.NewConditionEnhancer(h.location)
+	if err := enhancer.LoadConditions(); err != nil {
+		return nil, fmt.Errorf("failed to load conditions: %w", err)
+	}
+
+	schemaTypes = enhancer.EnhanceSchemas(schemaTypes)
+	setGroup(schemaTypes, h.group)
+
+	return schemaTypes, nil
+}
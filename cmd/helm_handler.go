@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/helm"
+)
+
+// HelmHandler renders a Helm chart offline and returns the CRDs found in the rendered manifests.
+type HelmHandler struct {
+	chart  string
+	values []string
+	group  string
+}
+
+// CRDs returns schemas from the rendered manifests of a chart folder or archive.
+func (h *HelmHandler) CRDs() ([]*pkg.SchemaType, error) {
+	if _, err := os.Stat(h.chart); os.IsNotExist(err) {
+		return nil, fmt.Errorf("chart under '%s' does not exist", h.chart)
+	}
+
+	manifests, err := helm.Render(h.chart, h.values)
+	if err != nil {
+		return nil, err
+	}
+
+	var crds []*pkg.SchemaType
+
+	for _, m := range manifests {
+		schemaTypes, err := pkg.DecodeRenderedSchemaTypes(m.Content, m.Name, os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract schema types: %w", err)
+		}
+
+		setGroup(schemaTypes, h.group)
+		crds = append(crds, schemaTypes...)
+	}
+
+	return crds, nil
+}
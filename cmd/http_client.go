@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+)
+
+// newHTTPOptions reads the TLS files of the flags. The options are shared by URL, archive,
+// OpenAPI and git sources.
+func newHTTPOptions(args *rootArgs) (fetcher.ClientOptions, error) {
+	opts := fetcher.ClientOptions{
+		Proxy:   args.proxy,
+		Timeout: args.httpTimeout,
+		Retries: args.httpRetries,
+		Backoff: fetcher.DefaultBackoff,
+	}
+
+	if (args.clientCert == "") != (args.clientKey == "") {
+		return opts, errors.New("client-cert-file and client-key-file must be set together")
+	}
+
+	for _, file := range []struct {
+		location string
+		content  *[]byte
+	}{
+		{location: args.caBundle, content: &opts.CABundle},
+		{location: args.clientCert, content: &opts.ClientCert},
+		{location: args.clientKey, content: &opts.ClientKey},
+	} {
+		if file.location == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Clean(file.location))
+		if err != nil {
+			return opts, fmt.Errorf("failed to read %s: %w", file.location, err)
+		}
+
+		*file.content = content
+	}
+
+	return opts, nil
+}
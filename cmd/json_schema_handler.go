@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+const defaultJSONSchemaVersion = "v1"
+
+// JSONSchemaHandler wraps plain JSON Schema documents into a single kind. Every document is a version of that kind.
+type JSONSchemaHandler struct {
+	locations []string
+	versions  []string
+	kind      string
+	apiGroup  string
+	group     string
+}
+
+// CRDs returns a schema type with a version for every JSON Schema document.
+func (h *JSONSchemaHandler) CRDs() ([]*pkg.SchemaType, error) {
+	if h.kind == "" {
+		return nil, errors.New("json-schema-kind must be set when using json-schema")
+	}
+
+	if len(h.versions) == 0 && len(h.locations) > 1 {
+		return nil, fmt.Errorf("got %d json-schema files without json-schema-version values, set one version per file", len(h.locations))
+	}
+
+	if len(h.versions) > 0 && len(h.versions) != len(h.locations) {
+		return nil, fmt.Errorf("got %d json-schema-version values for %d json-schema files, set one version per file", len(h.versions), len(h.locations))
+	}
+
+	schemaType := &pkg.SchemaType{Group: h.apiGroup, Kind: h.kind}
+
+	for i, location := range h.locations {
+		content, err := os.ReadFile(filepath.Clean(location))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		schema, err := pkg.ParseJSONSchema(content, location, os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+
+		version := defaultJSONSchemaVersion
+		if len(h.versions) > 0 {
+			version = h.versions[i]
+		}
+
+		schemaType.Versions = append(schemaType.Versions, &pkg.CRDVersion{Name: version, Schema: schema})
+	}
+
+	schemaTypes := []*pkg.SchemaType{schemaType}
+	setGroup(schemaTypes, h.group)
+
+	return schemaTypes, nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/kustomize"
+)
+
+// KustomizeHandler builds a kustomization and returns the CRDs found in the result.
+type KustomizeHandler struct {
+	location string
+	group    string
+}
+
+// CRDs returns schemas from the resources produced by building the kustomization.
+func (h *KustomizeHandler) CRDs() ([]*pkg.SchemaType, error) {
+	if _, err := os.Stat(h.location); os.IsNotExist(err) {
+		return nil, fmt.Errorf("kustomization under '%s' does not exist", h.location)
+	}
+
+	content, err := kustomize.Build(h.location)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaTypes, err := pkg.DecodeRenderedSchemaTypes(content, h.location, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract schema types: %w", err)
+	}
+
+	setGroup(schemaTypes, h.group)
+
+	return schemaTypes, nil
+}
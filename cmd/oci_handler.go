@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/oci"
+)
+
+// OCIHandler pulls an OCI artifact and returns the CRDs found in its layers.
+type OCIHandler struct {
+	ref    string
+	group  string
+	filter *filter.Filter
+}
+
+// CRDs returns schemas from the files of the artifact that match the filter.
+func (h *OCIHandler) CRDs() ([]*pkg.SchemaType, error) {
+	files, err := oci.Pull(h.ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var crds []*pkg.SchemaType
+
+	for _, f := range files {
+		if !h.filter.Match(f.Name) {
+			continue
+		}
+
+		decode := pkg.DecodeSchemaTypes
+		if f.Rendered {
+			decode = pkg.DecodeRenderedSchemaTypes
+		}
+
+		source := f.Layer + "/" + f.Name
+
+		schemaTypes, err := decode(f.Content, source, os.Stderr)
+		if err != nil {
+			if err := skipUndecodable(os.Stderr, source, err); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		setGroup(schemaTypes, h.group)
+		crds = append(crds, schemaTypes...)
+	}
+
+	return crds, nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/olm"
+)
+
+// OLMBundleHandler loads the CRDs of an Operator Lifecycle Manager bundle.
+type OLMBundleHandler struct {
+	location string
+	group    string
+}
+
+// CRDs returns schemas of the CRDs in the bundle after validating the alm-examples of its
+// ClusterServiceVersion against them.
+func (h *OLMBundleHandler) CRDs() ([]*pkg.SchemaType, error) {
+	bundle, err := olm.Load(h.location)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bundle.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s in %s, run `generate alm-examples` to regenerate them: %w", olm.ExamplesAnnotation, bundle.CSVPath, err)
+	}
+
+	schemaTypes, err := pkg.DecodeRenderedSchemaTypes(bundle.CRDContents(), h.location, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract schema types: %w", err)
+	}
+
+	setGroup(schemaTypes, h.group)
+
+	return schemaTypes, nil
+}
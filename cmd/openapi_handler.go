@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+)
+
+// OpenAPIHandler reads Kubernetes kinds from an OpenAPI v3 document on disk or behind a URL.
+type OpenAPIHandler struct {
+	location  string
+	username  string
+	password  string
+	token     string
+	group     string
+	cache     *cache.Cache
+	client    *http.Client
+	providers []credentials.Provider
+}
+
+// CRDs returns a schema for every kind of the document.
+func (h *OpenAPIHandler) CRDs() ([]*pkg.SchemaType, error) {
+	var (
+		content []byte
+		err     error
+	)
+
+	if strings.HasPrefix(h.location, "http://") || strings.HasPrefix(h.location, "https://") {
+		creds, err := credentials.Resolve(h.location, credentials.Credentials{
+			Username: h.username,
+			Password: h.password,
+			Token:    h.token,
+		}, h.providers...)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err = fetcher.NewFetcher(h.client, creds.Username, creds.Password, creds.Token).WithCache(h.cache).Fetch(h.location)
+		if err != nil {
+			return nil, creds.RedactError(fmt.Errorf("failed to fetch content: %w", err))
+		}
+	} else {
+		content, err = os.ReadFile(filepath.Clean(h.location))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	schemaTypes, err := pkg.ParseOpenAPIV3(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document %s: %w", credentials.RedactURL(h.location), err)
+	}
+
+	setGroup(schemaTypes, h.group)
+
+	return schemaTypes, nil
+}
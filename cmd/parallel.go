@@ -0,0 +1,25 @@
+package cmd
+
+import "golang.org/x/sync/errgroup"
+
+// runParallel calls fn for every index below count, at most parallel at a time, and returns the error of
+// every call at its index. A failed call doesn't stop the others.
+func runParallel(parallel, count int, fn func(i int) error) []error {
+	errs := make([]error, count)
+
+	var g errgroup.Group
+
+	g.SetLimit(max(parallel, 1))
+
+	for i := range count {
+		g.Go(func() error {
+			errs[i] = fn(i)
+
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return errs
+}
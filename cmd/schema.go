@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/util/json"
 
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
 	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
 )
 
@@ -64,33 +65,14 @@ func runGenerateSchema(_ *cobra.Command, _ []string) error {
 	}
 
 	for _, crd := range crds {
-		for _, v := range crd.Versions {
-			if v.Schema.ID == "" {
-				v.Schema.ID = "https://crdtoyaml.com/" + crd.Kind + "." + crd.Group + "." + v.Name + ".schema.json"
-			}
-
-			if v.Schema.Schema == "" {
-				v.Schema.Schema = "https://json-schema.org/draft/2020-12/schema"
-			}
-
-			schema := Schema{
-				JSONSchemaProps: v.Schema,
-				KubernetesGroupVersionKindList: []KindVersionGroup{
-					{
-						Kind:    crd.Kind,
-						Group:   crd.Group,
-						Version: v.Name,
-					},
-				},
-			}
-
-			content, err := json.Marshal(schema)
-			if err != nil {
-				return fmt.Errorf("failed to marshal schema: %w", err)
-			}
+		files, err := versionSchemas(crd)
+		if err != nil {
+			return err
+		}
 
+		for _, file := range files {
 			const perm = 0o600
-			if err := os.WriteFile(filepath.Join(schemaArgs.outputFolder, crd.Kind+"."+crd.Group+"."+v.Name+".schema.json"), content, perm); err != nil {
+			if err := os.WriteFile(filepath.Join(schemaArgs.outputFolder, file.name), file.content, perm); err != nil {
 				return fmt.Errorf("failed to write schema: %w", err)
 			}
 		}
@@ -98,3 +80,53 @@ func runGenerateSchema(_ *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// schemaFile is the JSON schema of a CRD version.
+type schemaFile struct {
+	name    string
+	version string
+	content []byte
+}
+
+// versionSchemas returns the JSON schema of every version of the CRD.
+func versionSchemas(crd *pkg.SchemaType) ([]schemaFile, error) {
+	files := make([]schemaFile, 0, len(crd.Versions))
+
+	for _, v := range crd.Versions {
+		// kinds of the core group, like ConfigMap, don't have a group.
+		name := crd.Kind + "." + crd.Group + "." + v.Name
+		if crd.Group == "" {
+			name = crd.Kind + "." + v.Name
+		}
+
+		// the defaults are set on a copy, so the CRD can still be rendered into other outputs.
+		props := *v.Schema
+		if props.ID == "" {
+			props.ID = "https://crdtoyaml.com/" + name + ".schema.json"
+		}
+
+		if props.Schema == "" {
+			props.Schema = "https://json-schema.org/draft/2020-12/schema"
+		}
+
+		schema := Schema{
+			JSONSchemaProps: &props,
+			KubernetesGroupVersionKindList: []KindVersionGroup{
+				{
+					Kind:    crd.Kind,
+					Group:   crd.Group,
+					Version: v.Name,
+				},
+			},
+		}
+
+		content, err := json.Marshal(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema: %w", err)
+		}
+
+		files = append(files, schemaFile{name: name + ".schema.json", version: v.Name, content: content})
+	}
+
+	return files, nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+)
+
+// siteCmd renders the HTML reference of every selected tag of a git repository.
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Generate a versioned HTML reference with a page per git tag and a version switcher.",
+	RunE:  runSite,
+}
+
+type siteCmdArgs struct {
+	tags       string
+	output     string
+	comments   bool
+	minimal    bool
+	skipRandom bool
+	cssFile    string
+	changes    bool
+}
+
+var siteArgs = &siteCmdArgs{}
+
+func init() {
+	generateCmd.AddCommand(siteCmd)
+	f := siteCmd.Flags()
+	f.StringVar(&siteArgs.tags, "tags", "", "Glob pattern of the git tags to render, e.g. 'v1.*'. Default is every tag.")
+	f.StringVarP(&siteArgs.output, "output", "o", "", "The folder to write the site into. Every tag is rendered into <output>/<tag>/index.html.")
+	f.BoolVarP(&siteArgs.comments, "comments", "m", false, "If set, it will add descriptions as comments to each line where available.")
+	f.BoolVarP(&siteArgs.minimal, "minimal", "l", false, "If set, only the minimal required example yaml is generated.")
+	f.BoolVar(&siteArgs.skipRandom, "no-random", false, "Skip generating random values that satisfy the property patterns.")
+	f.StringVar(&siteArgs.cssFile, "css-file", "", "Path to a custom CSS file to inject into every page.")
+	f.BoolVar(&siteArgs.changes, "changes", false, "If set, properties that changed since the previous tag are marked with a badge.")
+}
+
+func runSite(_ *cobra.Command, _ []string) error {
+	if args.gitURL == "" && args.gitRepo == "" {
+		return errors.New("git-url or git-repo must be set")
+	}
+
+	if siteArgs.output == "" {
+		return errors.New("output must be set to a folder")
+	}
+
+	if err := pkg.LoadTemplates(); err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	var customCSS string
+
+	if siteArgs.cssFile != "" {
+		var err error
+
+		customCSS, err = pkg.SanitizeCSS(siteArgs.cssFile)
+		if err != nil {
+			return fmt.Errorf("failed to process CSS file: %w", err)
+		}
+	}
+
+	fileFilter, err := filter.New(args.include, args.exclude)
+	if err != nil {
+		return fmt.Errorf("failed to construct file filter: %w", err)
+	}
+
+	remoteCache, err := newCache(args)
+	if err != nil {
+		return fmt.Errorf("failed to construct cache: %w", err)
+	}
+
+	httpOpts, err := newHTTPOptions(args)
+	if err != nil {
+		return err
+	}
+
+	providers, err := newCredentialProviders(args)
+	if err != nil {
+		return err
+	}
+
+	handler := newGitHandler(args, fileFilter, remoteCache, httpOpts, providers)
+
+	tags, err := handler.tags(siteArgs.tags)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return fmt.Errorf("no tags match '%s'", siteArgs.tags)
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	validator := pkg.NewSchemaValidator()
+
+	var previous []*pkg.SchemaType
+
+	for i, tag := range tags {
+		crds, err := handler.gatherSchemaTypesForRef(tag.Commit)
+		if err != nil {
+			return fmt.Errorf("failed to get CRDs at %s: %w", tag.Name, err)
+		}
+
+		opts := pkg.RenderOpts{
+			Comments:  siteArgs.comments,
+			Minimal:   siteArgs.minimal,
+			Random:    siteArgs.skipRandom,
+			CustomCSS: customCSS,
+			Releases:  pkg.ReleaseLinks(names, tag.Name),
+		}
+
+		if siteArgs.changes && i > 0 {
+			opts.Changes, err = validator.CompareSchemaTypes(tags[i-1].Name, previous, tag.Name, crds)
+			if err != nil {
+				return fmt.Errorf("failed to compare CRDs of %s and %s: %w", tags[i-1].Name, tag.Name, err)
+			}
+		}
+
+		location := filepath.Join(siteArgs.output, filepath.FromSlash(pkg.SitePagePath(tag.Name)))
+		if err := writeFile(location, func(w io.Writer) error {
+			return pkg.RenderContent(nopCloser{Writer: w}, crds, opts)
+		}); err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(os.Stderr, "Rendered %s with %d CRDs\n", tag.Name, len(crds))
+
+		previous = crds
+	}
+
+	return writeFile(filepath.Join(siteArgs.output, pkg.SiteIndex), func(w io.Writer) error {
+		return pkg.RenderSiteIndex(w, pkg.ReleaseLinks(names, ""))
+	})
+}
@@ -5,11 +5,7 @@ import (
 	"io"
 	"os"
 
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
-
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
-	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
 )
 
 type StdInHandler struct {
@@ -22,28 +18,12 @@ func (s *StdInHandler) CRDs() ([]*pkg.SchemaType, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	content, err = sanitize.Sanitize(content)
+	schemaTypes, err := pkg.DecodeSchemaTypes(content, "stdin", os.Stderr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sanitize content: %w", err)
-	}
-
-	crd := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal(content, crd); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal into custom resource definition: %w", err)
+		return nil, fmt.Errorf("failed to extract schema types: %w", err)
 	}
 
-	schemaType, err := pkg.ExtractSchemaType(crd)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract schema type: %w", err)
-	}
-
-	if schemaType == nil {
-		return nil, nil
-	}
-
-	if s.group != "" {
-		schemaType.Rendering = pkg.Rendering{Group: s.group}
-	}
+	setGroup(schemaTypes, s.group)
 
-	return []*pkg.SchemaType{schemaType}, nil
+	return schemaTypes, nil
 }
@@ -3,59 +3,48 @@ package cmd
 import (
 	"fmt"
 	"net/http"
-	"time"
-
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
+	"os"
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
 	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
-	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
 )
 
-const timeout = 10
-
 type URLHandler struct {
-	url      string
-	username string
-	password string
-	token    string
-	group    string
+	url       string
+	username  string
+	password  string
+	token     string
+	group     string
+	cache     *cache.Cache
+	client    *http.Client
+	providers []credentials.Provider
 }
 
 func (h *URLHandler) CRDs() ([]*pkg.SchemaType, error) {
-	client := http.DefaultClient
-	client.Timeout = timeout * time.Second
-
-	f := fetcher.NewFetcher(client, h.username, h.password, h.token)
-
-	content, err := f.Fetch(h.url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch content: %w", err)
-	}
-
-	content, err = sanitize.Sanitize(content)
+	creds, err := credentials.Resolve(h.url, credentials.Credentials{
+		Username: h.username,
+		Password: h.password,
+		Token:    h.token,
+	}, h.providers...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sanitize content: %w", err)
+		return nil, err
 	}
 
-	crd := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal(content, crd); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal into custom resource definition: %w", err)
-	}
+	f := fetcher.NewFetcher(h.client, creds.Username, creds.Password, creds.Token).WithCache(h.cache)
 
-	schemaType, err := pkg.ExtractSchemaType(crd)
+	content, err := f.Fetch(h.url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract schema type: %w", err)
+		return nil, creds.RedactError(fmt.Errorf("failed to fetch content: %w", err))
 	}
 
-	if schemaType == nil {
-		return nil, nil
+	schemaTypes, err := pkg.DecodeSchemaTypes(content, credentials.RedactURL(h.url), os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract schema types: %w", err)
 	}
 
-	if h.group != "" {
-		schemaType.Rendering = pkg.Rendering{Group: h.group}
-	}
+	setGroup(schemaTypes, h.group)
 
-	return []*pkg.SchemaType{schemaType}, nil
+	return schemaTypes, nil
 }
@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
 )
 
 var validateCmd = &cobra.Command{
@@ -26,6 +29,8 @@ type validateArgs struct {
 	toVersion      string
 	outputFormat   string
 	failOnBreaking bool
+	base           string
+	head           string
 }
 
 var valArgs = &validateArgs{}
@@ -42,9 +47,15 @@ func init() {
 	f.StringVar(&valArgs.toVersion, "to", "", "Target version to compare to (e.g., v1beta1)")
 	f.StringVarP(&valArgs.outputFormat, "output", "o", "text", "Output format: text, json, yaml")
 	f.BoolVar(&valArgs.failOnBreaking, "fail-on-breaking", false, "Exit with non-zero code if breaking changes detected")
+	f.StringVar(&valArgs.base, "base", "", "Git ref to compare the CRDs of --git-url or --git-repo from (e.g., v1.4.0)")
+	f.StringVar(&valArgs.head, "head", "", "Git ref to compare the CRDs of --git-url or --git-repo to (e.g., main). Default is the default branch, or the working tree with --git-worktree.")
 }
 
 func runSchemaValidation(cmd *cobra.Command, _ []string) error {
+	if valArgs.base != "" || valArgs.head != "" {
+		return runRefComparison()
+	}
+
 	handler, err := constructHandler(args)
 	if err != nil {
 		return fmt.Errorf("failed to get handler: %w", err)
@@ -79,7 +90,94 @@ func runSchemaValidation(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
-func outputValidationReport(report *pkg.ValidationReport, format string) error {
+// runRefComparison compares the CRDs of a git repository at two refs.
+func runRefComparison() error {
+	if valArgs.base == "" {
+		return errors.New("--base must be set to compare git refs")
+	}
+
+	if args.gitURL == "" && args.gitRepo == "" {
+		return errors.New("--base and --head require --git-url or --git-repo")
+	}
+
+	if args.gitWorktree && valArgs.head != "" {
+		return errors.New("--head can't be used with --git-worktree, the working tree is compared")
+	}
+
+	if valArgs.fromVersion != "" || valArgs.toVersion != "" {
+		return errors.New("--from and --to can't be used with --base and --head, matching versions are compared")
+	}
+
+	fileFilter, err := filter.New(args.include, args.exclude)
+	if err != nil {
+		return fmt.Errorf("failed to construct file filter: %w", err)
+	}
+
+	remoteCache, err := newCache(args)
+	if err != nil {
+		return fmt.Errorf("failed to construct cache: %w", err)
+	}
+
+	httpOpts, err := newHTTPOptions(args)
+	if err != nil {
+		return err
+	}
+
+	providers, err := newCredentialProviders(args)
+	if err != nil {
+		return err
+	}
+
+	handler := newGitHandler(args, fileFilter, remoteCache, httpOpts, providers)
+
+	base, err := handler.crdsForRef(valArgs.base)
+	if err != nil {
+		return fmt.Errorf("failed to get CRDs at %s: %w", valArgs.base, err)
+	}
+
+	headName := cmp.Or(valArgs.head, "HEAD")
+
+	var head []*pkg.SchemaType
+
+	if args.gitWorktree {
+		headName = "worktree"
+		head, err = handler.crdsForWorktree()
+	} else {
+		head, err = handler.crdsForRef(valArgs.head)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get CRDs at %s: %w", headName, err)
+	}
+
+	if len(base) == 0 && len(head) == 0 {
+		return errors.New("no CRDs found")
+	}
+
+	report, err := pkg.NewSchemaValidator().CompareSchemaTypes(valArgs.base, base, headName, head)
+	if err != nil {
+		return fmt.Errorf("failed to compare CRDs: %w", err)
+	}
+
+	if err := outputValidationReport(report, valArgs.outputFormat); err != nil {
+		return fmt.Errorf("failed to output validation report: %w", err)
+	}
+
+	if valArgs.failOnBreaking && report.HasBreakingChanges() {
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// report is implemented by the validation and the comparison report.
+type report interface {
+	OutputJSON(w io.Writer) error
+	OutputYAML(w io.Writer) error
+	OutputText(w io.Writer) error
+}
+
+func outputValidationReport(report report, format string) error {
 	switch format {
 	case "json":
 		return report.OutputJSON(os.Stdout)
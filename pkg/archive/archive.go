@@ -0,0 +1,164 @@
+// Package archive walks the files of tar, gzip compressed tar and zip archives.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// maxEntrySize limits the size of a single file to protect against decompression bombs.
+const maxEntrySize = 64 << 20
+
+// tarMagicOffset is the offset of the `ustar` magic in a tar header.
+const tarMagicOffset = 257
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+	tarMagic  = []byte("ustar")
+)
+
+// MatchFunc selects the regular files of an archive by their slash separated path. Files that don't
+// match are skipped without being read.
+type MatchFunc func(name string) bool
+
+// WalkFunc is called for every matching regular file in an archive with its slash separated path.
+type WalkFunc func(name string, content []byte) error
+
+// Walk detects the format of the archive from its content and calls fn for every regular file that
+// matches. A nil match selects every file. Tar archives, compressed or not, are streamed. Zip archives
+// are spooled to a temporary file because their index is at the end.
+func Walk(r io.Reader, match MatchFunc, fn WalkFunc) error {
+	if match == nil {
+		match = func(string) bool { return true }
+	}
+
+	br := bufio.NewReader(r)
+
+	header, err := br.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read archive header: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+
+		defer func() {
+			_ = gz.Close()
+		}()
+
+		return walkTar(gz, match, fn)
+	case bytes.HasPrefix(header, zipMagic):
+		return walkZip(br, match, fn)
+	case len(header) > tarMagicOffset && bytes.HasPrefix(header[tarMagicOffset:], tarMagic):
+		return walkTar(br, match, fn)
+	default:
+		return errors.New("unknown archive format, supported formats are tar, tar.gz and zip")
+	}
+}
+
+func walkTar(r io.Reader, match MatchFunc, fn WalkFunc) error {
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read tar entry: %w", err)
+		}
+
+		name := cleanName(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !match(name) {
+			continue
+		}
+
+		content, err := readEntry(tr, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(name, content); err != nil {
+			return err
+		}
+	}
+}
+
+func walkZip(r io.Reader, match MatchFunc, fn WalkFunc) error {
+	tmp, err := os.CreateTemp("", "archive-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for zip archive: %w", err)
+	}
+
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return fmt.Errorf("failed to open zip archive: %w", err)
+	}
+
+	for _, f := range zr.File {
+		name := cleanName(f.Name)
+		if !f.Mode().IsRegular() || !match(name) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open zip entry %s: %w", f.Name, err)
+		}
+
+		content, err := readEntry(rc, f.Name)
+		_ = rc.Close()
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(name, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readEntry(r io.Reader, name string) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxEntrySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive entry %s: %w", name, err)
+	}
+
+	if len(content) > maxEntrySize {
+		return nil, fmt.Errorf("archive entry %s is larger than %d bytes", name, maxEntrySize)
+	}
+
+	return content, nil
+}
+
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
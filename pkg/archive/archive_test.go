@@ -0,0 +1,206 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var files = map[string]string{
+	"crds/a.yaml":    "kind: A",
+	"./crds/b.json":  `{"kind": "B"}`,
+	"docs/README.md": "# readme",
+}
+
+func tarArchive(t *testing.T) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "crds/", Typeflag: tar.TypeDir, Mode: 0o755}))
+
+	for _, name := range []string{"crds/a.yaml", "./crds/b.json", "docs/README.md"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(files[name]))}))
+		_, err := tw.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	return buf.Bytes()
+}
+
+func gzipArchive(t *testing.T) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	_, err := gw.Write(tarArchive(t))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	_, err := zw.Create("crds/")
+	require.NoError(t, err)
+
+	for _, name := range []string{"crds/a.yaml", "./crds/b.json", "docs/README.md"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func TestWalk(t *testing.T) {
+	testCases := []struct {
+		name    string
+		archive func(t *testing.T) []byte
+	}{
+		{name: "tar", archive: tarArchive},
+		{name: "tar.gz", archive: gzipArchive},
+		{name: "zip", archive: zipArchive},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := map[string]string{}
+
+			err := Walk(bytes.NewReader(tc.archive(t)), nil, func(name string, content []byte) error {
+				got[name] = string(content)
+
+				return nil
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]string{
+				"crds/a.yaml":    "kind: A",
+				"crds/b.json":    `{"kind": "B"}`,
+				"docs/README.md": "# readme",
+			}, got)
+		})
+	}
+}
+
+func TestWalkStopsOnError(t *testing.T) {
+	calls := 0
+
+	err := Walk(bytes.NewReader(gzipArchive(t)), nil, func(string, []byte) error {
+		calls++
+
+		return errors.New("stop")
+	})
+	require.EqualError(t, err, "stop")
+	assert.Equal(t, 1, calls)
+}
+
+func TestWalkUnknownFormat(t *testing.T) {
+	err := Walk(bytes.NewReader([]byte("kind: CustomResourceDefinition")), nil, func(string, []byte) error {
+		return nil
+	})
+	require.ErrorContains(t, err, "unknown archive format")
+}
+
+// largeArchive streams a tar archive with a CRD and a binary that is larger than an entry may be.
+func largeArchive(t *testing.T) io.Reader {
+	t.Helper()
+
+	r, w := io.Pipe()
+	// the writer is stopped if the archive isn't read to the end.
+	t.Cleanup(func() { _ = r.Close() })
+
+	go func() {
+		tw := tar.NewWriter(w)
+
+		write := func(name string, size int64, content io.Reader) error {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o755, Size: size}); err != nil {
+				return err
+			}
+
+			_, err := io.Copy(tw, content)
+
+			return err
+		}
+
+		err := write("bin/operator", maxEntrySize+1, io.LimitReader(zeros{}, maxEntrySize+1))
+		if err == nil {
+			err = write("crds/a.yaml", int64(len("kind: A")), strings.NewReader("kind: A"))
+		}
+
+		if err == nil {
+			err = tw.Close()
+		}
+
+		_ = w.CloseWithError(err)
+	}()
+
+	return r
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+
+	return len(p), nil
+}
+
+func TestWalkSkipsEntriesBeforeReading(t *testing.T) {
+	got := map[string]string{}
+
+	err := Walk(largeArchive(t), func(name string) bool { return strings.HasPrefix(name, "crds/") }, func(name string, content []byte) error {
+		got[name] = string(content)
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"crds/a.yaml": "kind: A"}, got)
+
+	err = Walk(largeArchive(t), nil, func(string, []byte) error { return nil })
+	require.ErrorContains(t, err, "archive entry bin/operator is larger than")
+}
+
+func TestWalkZipRemovesSpooledArchive(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	var names []string
+
+	// the zip archive is spooled while it is walked.
+	err := Walk(bytes.NewReader(zipArchive(t)), nil, func(name string, _ []byte) error {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		names = append(names, name)
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, names, 3)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
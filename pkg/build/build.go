@@ -0,0 +1,241 @@
+// Package build writes the files of a build incrementally. Every file is keyed on a hash of the inputs
+// it's rendered from, and is only rendered again if that hash changed or the file was modified since
+// the previous build. The files of a build are recorded in a manifest.
+package build
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// StatusBuilt is the status of a file that was rendered in this build.
+	StatusBuilt = "built"
+	// StatusUnchanged is the status of a file whose inputs didn't change since the previous build.
+	StatusUnchanged = "unchanged"
+
+	dirPerm  = 0o755
+	filePerm = 0o644
+)
+
+// File is a file produced by a build.
+type File struct {
+	// Path of the file.
+	Path string `json:"path"`
+	// Format of the output the file belongs to.
+	Format string `json:"format"`
+	// Kind and Group of the CRD the file is rendered from. They're empty for files of several CRDs.
+	Kind  string `json:"kind,omitempty"`
+	Group string `json:"group,omitempty"`
+	// InputHash is the hash of the inputs the file is rendered from.
+	InputHash string `json:"inputHash"`
+	// SHA256 is the digest of the content of the file.
+	SHA256 string `json:"sha256"`
+	// Status is StatusBuilt or StatusUnchanged.
+	Status string `json:"status"`
+}
+
+// Manifest lists the files of a build.
+type Manifest struct {
+	// Version of the tool that produced the build.
+	Version string `json:"version,omitempty"`
+	// Files are the files of the build, sorted by path.
+	Files []File `json:"files"`
+	// Removed are the files of the previous build that this build doesn't produce anymore.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// LoadManifest reads the manifest at path. A missing manifest is an empty one.
+func LoadManifest(path string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Manifest{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Write writes the manifest to path.
+func (m *Manifest) Write(path string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return fmt.Errorf("failed to create folder for manifest: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), filePerm); err != nil { //nolint:gosec // the manifest is published.
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Counts returns the number of built and unchanged files.
+func (m *Manifest) Counts() (built, unchanged int) {
+	for _, f := range m.Files {
+		if f.Status == StatusBuilt {
+			built++
+		} else {
+			unchanged++
+		}
+	}
+
+	return built, unchanged
+}
+
+// Builder renders the files of a build.
+type Builder struct {
+	version  string
+	force    bool
+	previous map[string]File
+	files    map[string]File
+}
+
+// New returns a builder that skips the files of the previous build whose inputs didn't change. With
+// force every file is rendered. version is recorded in the manifest.
+func New(previous *Manifest, version string, force bool) *Builder {
+	b := &Builder{
+		version:  version,
+		force:    force,
+		previous: map[string]File{},
+		files:    map[string]File{},
+	}
+
+	if previous != nil {
+		for _, f := range previous.Files {
+			b.previous[filepath.Clean(f.Path)] = f
+		}
+	}
+
+	return b
+}
+
+// File renders file.Path with render, unless the previous build rendered it from the same input hash
+// and it wasn't modified since. Path, Format and InputHash of the file must be set. A file that's
+// produced twice in a build is rendered again, so the last one wins, like samples of kinds with the
+// same name.
+func (b *Builder) File(file File, render func(w io.Writer) error) error {
+	file.Path = filepath.Clean(file.Path)
+
+	_, produced := b.files[file.Path]
+
+	if previous, ok := b.previous[file.Path]; ok && !produced && !b.force && previous.InputHash == file.InputHash {
+		if digest, err := fileDigest(file.Path); err == nil && digest == previous.SHA256 {
+			file.SHA256 = digest
+			file.Status = StatusUnchanged
+			b.files[file.Path] = file
+
+			return nil
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := render(buf); err != nil {
+		return fmt.Errorf("failed to render %s: %w", file.Path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file.Path), dirPerm); err != nil {
+		return fmt.Errorf("failed to create folder for %s: %w", file.Path, err)
+	}
+
+	if err := os.WriteFile(file.Path, buf.Bytes(), filePerm); err != nil { //nolint:gosec // outputs are published.
+		return fmt.Errorf("failed to write %s: %w", file.Path, err)
+	}
+
+	file.SHA256 = digest(buf.Bytes())
+	file.Status = StatusBuilt
+	b.files[file.Path] = file
+
+	return nil
+}
+
+// Finish removes the files of the previous build that weren't produced by this one, unless they were
+// modified since, and returns the manifest of the build.
+func (b *Builder) Finish() (*Manifest, error) {
+	m := &Manifest{Version: b.version, Files: make([]File, 0, len(b.files))}
+
+	for _, f := range b.files {
+		m.Files = append(m.Files, f)
+	}
+
+	slices.SortFunc(m.Files, func(a, b File) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	var errs []error
+
+	for path, previous := range b.previous {
+		if _, ok := b.files[path]; ok {
+			continue
+		}
+
+		if digest, err := fileDigest(path); err != nil || digest != previous.SHA256 {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove stale file %s: %w", path, err))
+
+			continue
+		}
+
+		m.Removed = append(m.Removed, path)
+	}
+
+	slices.Sort(m.Removed)
+
+	return m, errors.Join(errs...)
+}
+
+// Hash returns a hash of the JSON encoding of the values.
+func Hash(values ...any) (string, error) {
+	h := sha256.New()
+
+	for _, v := range values {
+		content, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash inputs: %w", err)
+		}
+
+		h.Write(content)
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func fileDigest(path string) (string, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	return digest(content), nil
+}
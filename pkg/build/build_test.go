@@ -0,0 +1,126 @@
+package build
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderIsIncremental(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.json")
+
+	renders := map[string]int{}
+
+	run := func(files map[string]string, force bool) *Manifest {
+		previous, err := LoadManifest(manifest)
+		require.NoError(t, err)
+
+		b := New(previous, "v1.0.0", force)
+
+		for name, input := range files {
+			hash, err := Hash(input)
+			require.NoError(t, err)
+
+			require.NoError(t, b.File(File{Path: filepath.Join(dir, name), Format: "yaml", InputHash: hash}, func(w io.Writer) error {
+				renders[name]++
+				_, err := io.WriteString(w, input)
+
+				return err
+			}))
+		}
+
+		m, err := b.Finish()
+		require.NoError(t, err)
+		require.NoError(t, m.Write(manifest))
+
+		return m
+	}
+
+	m := run(map[string]string{"a/one.yaml": "one", "two.yaml": "two"}, false)
+	built, unchanged := m.Counts()
+	assert.Equal(t, 2, built)
+	assert.Zero(t, unchanged)
+	assert.Equal(t, "v1.0.0", m.Version)
+	assert.Equal(t, filepath.Join(dir, "a/one.yaml"), m.Files[0].Path, "files are sorted by path")
+
+	// only the changed input is rendered again.
+	m = run(map[string]string{"a/one.yaml": "one", "two.yaml": "two v2"}, false)
+	built, unchanged = m.Counts()
+	assert.Equal(t, 1, built)
+	assert.Equal(t, 1, unchanged)
+	assert.Equal(t, map[string]int{"a/one.yaml": 1, "two.yaml": 2}, renders)
+
+	// a modified file is rendered again.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a/one.yaml"), []byte("edited"), 0o600))
+	run(map[string]string{"a/one.yaml": "one", "two.yaml": "two v2"}, false)
+	assert.Equal(t, 2, renders["a/one.yaml"])
+
+	content, err := os.ReadFile(filepath.Join(dir, "a/one.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(content))
+
+	// force renders everything.
+	run(map[string]string{"a/one.yaml": "one", "two.yaml": "two v2"}, true)
+	assert.Equal(t, map[string]int{"a/one.yaml": 3, "two.yaml": 3}, renders)
+
+	// files that aren't produced anymore are removed.
+	m = run(map[string]string{"a/one.yaml": "one"}, false)
+	assert.Equal(t, []string{filepath.Join(dir, "two.yaml")}, m.Removed)
+	assert.NoFileExists(t, filepath.Join(dir, "two.yaml"))
+}
+
+func TestBuilderKeepsModifiedStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "one.yaml")
+
+	b := New(nil, "", false)
+	require.NoError(t, b.File(File{Path: path, InputHash: "a"}, func(w io.Writer) error {
+		_, err := io.WriteString(w, "one")
+
+		return err
+	}))
+
+	m, err := b.Finish()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("edited"), 0o600))
+
+	m, err = New(m, "", false).Finish()
+	require.NoError(t, err)
+	assert.Empty(t, m.Removed)
+	assert.FileExists(t, path)
+}
+
+func TestBuilderLastDuplicateWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "one.yaml")
+	write := func(content string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+
+			return err
+		}
+	}
+
+	b := New(nil, "", false)
+	require.NoError(t, b.File(File{Path: path, InputHash: "a"}, write("first")))
+	require.NoError(t, b.File(File{Path: path, InputHash: "b"}, write("second")))
+
+	m, err := b.Finish()
+	require.NoError(t, err)
+	require.Len(t, m.Files, 1)
+	assert.Equal(t, "b", m.Files[0].InputHash)
+
+	// the second file isn't skipped although its hash is unchanged, because the first one overwrote it.
+	b = New(m, "", false)
+	require.NoError(t, b.File(File{Path: path, InputHash: "a"}, write("first")))
+	require.NoError(t, b.File(File{Path: path, InputHash: "b"}, write("second")))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+}
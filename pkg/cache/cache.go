@@ -0,0 +1,343 @@
+// Package cache keeps the content of remote sources on disk between runs. HTTP responses are stored
+// with their validators so they can be revalidated, and git repositories are kept as bare mirrors
+// that are updated with a fetch.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+)
+
+const (
+	// dirName is the folder created in the user cache folder.
+	dirName = "crd-to-sample-yaml"
+
+	httpFolder = "http"
+	gitFolder  = "git"
+
+	metadataExt = ".json"
+	bodyExt     = ".body"
+
+	dirPerm = 0o755
+)
+
+// Entry contains the validators of a cached HTTP response.
+type Entry struct {
+	// URL of the response. Credentials in it are redacted when the entry is stored.
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// Cache is a folder of cached remote content.
+type Cache struct {
+	dir     string
+	offline bool
+	refresh bool
+
+	mu sync.Mutex
+	// refreshed contains the git mirrors that were already removed in this run.
+	refreshed map[string]bool
+	// locks serialize the sources of a run that use the same entry.
+	locks map[string]*sync.Mutex
+}
+
+// DefaultDir returns the cache folder under the user cache folder, `$XDG_CACHE_HOME` or `~/.cache` on Linux.
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, dirName)
+}
+
+// New returns a cache in dir. In offline mode nothing is fetched and only cached content is used. With
+// refresh, cached content is ignored and fetched again.
+func New(dir string, offline, refresh bool) (*Cache, error) {
+	if offline && refresh {
+		return nil, errors.New("offline and refresh can't be used together")
+	}
+
+	if dir == "" {
+		return nil, errors.New("cache folder must be set")
+	}
+
+	return &Cache{dir: dir, offline: offline, refresh: refresh, refreshed: map[string]bool{}, locks: map[string]*sync.Mutex{}}, nil
+}
+
+// Offline returns true if content must not be fetched.
+func (c *Cache) Offline() bool {
+	return c.offline
+}
+
+// HTTP returns the cached response of the URL. The entry is nil if the URL isn't cached or
+// refresh is set.
+func (c *Cache) HTTP(url string) (*Entry, []byte, error) {
+	entry, body, err := c.OpenHTTP(url)
+	if err != nil || entry == nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cached content of %s: %w", credentials.RedactURL(url), err)
+	}
+
+	return entry, data, nil
+}
+
+// OpenHTTP is like HTTP, but returns the cached content as an open file so it isn't read into
+// memory. The caller closes the file.
+func (c *Cache) OpenHTTP(url string) (*Entry, *os.File, error) {
+	if c.refresh {
+		return nil, nil, nil
+	}
+
+	metadata, body := c.httpPaths(url)
+	url = credentials.RedactURL(url)
+
+	content, err := os.ReadFile(metadata)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cache entry of %s: %w", url, err)
+	}
+
+	entry := &Entry{}
+	if err := json.Unmarshal(content, entry); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse cache entry of %s: %w", url, err)
+	}
+
+	file, err := os.Open(body)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cached content of %s: %w", url, err)
+	}
+
+	if err := touch(metadata); err != nil {
+		_ = file.Close()
+
+		return nil, nil, err
+	}
+
+	return entry, file, nil
+}
+
+// StoreHTTP stores the response of the URL.
+func (c *Cache) StoreHTTP(entry *Entry, data []byte) error {
+	return c.storeHTTP(entry, bytes.NewReader(data))
+}
+
+// StoreHTTPReader stores the response of the URL while it's read from r, and returns the stored
+// content as an open file. The caller closes the file.
+func (c *Cache) StoreHTTPReader(entry *Entry, r io.Reader) (*os.File, error) {
+	if err := c.storeHTTP(entry, r); err != nil {
+		return nil, err
+	}
+
+	_, body := c.httpPaths(entry.URL)
+
+	file, err := os.Open(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached content of %s: %w", credentials.RedactURL(entry.URL), err)
+	}
+
+	return file, nil
+}
+
+func (c *Cache) storeHTTP(entry *Entry, r io.Reader) error {
+	metadata, body := c.httpPaths(entry.URL)
+
+	// the entry is found by the hash of the full URL, so credentials don't have to be stored.
+	stored := *entry
+	stored.URL = credentials.RedactURL(entry.URL)
+
+	if err := os.MkdirAll(filepath.Dir(metadata), dirPerm); err != nil {
+		return fmt.Errorf("failed to create cache folder: %w", err)
+	}
+
+	content, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry of %s: %w", stored.URL, err)
+	}
+
+	// the body is written first, so an entry without a body is never found.
+	if err := writeFile(body, r); err != nil {
+		return fmt.Errorf("failed to cache content of %s: %w", stored.URL, err)
+	}
+
+	if err := writeFile(metadata, bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("failed to cache entry of %s: %w", stored.URL, err)
+	}
+
+	return nil
+}
+
+// GitDir returns the folder of the bare mirror of the repository. The folder doesn't exist if the
+// repository isn't cached yet. With refresh, an existing mirror is removed the first time it's requested.
+func (c *Cache) GitDir(url string) (string, error) {
+	dir := filepath.Join(c.dir, gitFolder, key(url))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.refresh && !c.refreshed[dir] {
+		if err := os.RemoveAll(dir); err != nil {
+			return "", fmt.Errorf("failed to remove cached repository %s: %w", credentials.RedactURL(url), err)
+		}
+
+		c.refreshed[dir] = true
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), dirPerm); err != nil {
+		return "", fmt.Errorf("failed to create cache folder: %w", err)
+	}
+
+	if err := touch(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// Lock waits until no other source of the run uses the entry of the URL and locks it. Sources that are
+// loaded concurrently lock a git mirror while they fetch and read it.
+func (c *Cache) Lock(url string) (unlock func()) {
+	c.mu.Lock()
+
+	l, ok := c.locks[url]
+	if !ok {
+		l = &sync.Mutex{}
+		c.locks[url] = l
+	}
+
+	c.mu.Unlock()
+
+	l.Lock()
+
+	return l.Unlock
+}
+
+// Prune removes the entries that weren't used for longer than maxAge and returns their paths.
+// A zero maxAge removes every entry.
+func (c *Cache) Prune(maxAge time.Duration) ([]string, error) {
+	var removed []string
+
+	for _, folder := range []string{httpFolder, gitFolder} {
+		entries, err := os.ReadDir(filepath.Join(c.dir, folder))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return removed, fmt.Errorf("failed to read cache folder: %w", err)
+		}
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				return removed, fmt.Errorf("failed to read cache entry %s: %w", entry.Name(), err)
+			}
+
+			path := filepath.Join(c.dir, folder, entry.Name())
+
+			// the body of an HTTP entry is removed together with its metadata.
+			if filepath.Ext(path) == bodyExt {
+				if _, err := os.Stat(metadataPath(path)); err == nil {
+					continue
+				}
+			} else if maxAge > 0 && time.Since(info.ModTime()) <= maxAge {
+				continue
+			}
+
+			if err := os.RemoveAll(path); err != nil {
+				return removed, fmt.Errorf("failed to remove cache entry %s: %w", path, err)
+			}
+
+			if folder == httpFolder && filepath.Ext(path) == metadataExt {
+				if err := os.Remove(bodyPath(path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return removed, fmt.Errorf("failed to remove cache entry %s: %w", path, err)
+				}
+			}
+
+			removed = append(removed, path)
+		}
+	}
+
+	return removed, nil
+}
+
+func (c *Cache) httpPaths(url string) (string, string) {
+	metadata := filepath.Join(c.dir, httpFolder, key(url)+metadataExt)
+
+	return metadata, bodyPath(metadata)
+}
+
+func bodyPath(metadata string) string {
+	return strings.TrimSuffix(metadata, metadataExt) + bodyExt
+}
+
+func metadataPath(body string) string {
+	return strings.TrimSuffix(body, bodyExt) + metadataExt
+}
+
+func key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// touch marks an entry as used so it isn't pruned.
+func touch(path string) error {
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		return fmt.Errorf("failed to update cache entry %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// writeFile writes through a temporary file so concurrent readers never see partial content.
+func writeFile(path string, r io.Reader) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,182 @@
+// Package config defines the configuration file of the `--config` flag. The file groups CRDs from any
+// source, configures how every group is rendered and where the output is written. It's validated
+// against a JSON Schema that is generated from these types.
+package config
+
+const (
+	// FormatHTML renders a single HTML page.
+	FormatHTML = "html"
+	// FormatMarkdown renders a Markdown page per CRD and an index into a folder.
+	FormatMarkdown = "markdown"
+	// FormatYAML renders a sample per CRD into a folder.
+	FormatYAML = "yaml"
+	// FormatSchema renders a JSON Schema per CRD version into a folder.
+	FormatSchema = "schema"
+	// FormatValidation renders a report of the changes between consecutive versions of every CRD into
+	// a folder.
+	FormatValidation = "validation"
+)
+
+// RenderConfig defines a configuration for the resulting rendered HTML content.
+type RenderConfig struct {
+	// APIGroups are the groups the CRDs are rendered in.
+	APIGroups []APIGroups `json:"apiGroups"`
+	// Outputs are rendered by `build`, and by `generate crd` instead of its format and output flags.
+	Outputs []Output `json:"outputs,omitempty"`
+}
+
+// APIGroups defines groups by which grouping will happen in the resulting HTML output.
+type APIGroups struct {
+	// Name of the group. It replaces the API group of its CRDs in the output.
+	Name string `json:"name"`
+	// Description is rendered under the name of the group.
+	Description string `json:"description,omitempty"`
+	// Files are paths of CRD files.
+	Files []string `json:"files,omitempty"`
+	// Folders are searched for CRD files.
+	Folders []string `json:"folders,omitempty"`
+	// URLs point at CRD files.
+	URLs []URLs `json:"urls,omitempty"`
+	// GitURLs are repositories that are searched for CRD files.
+	GitURLs []GITUrls `json:"gitUrls,omitempty"`
+	// Archives are tar, tar.gz or zip archives that are searched for CRD files.
+	Archives []Archives `json:"archives,omitempty"`
+	// Kube are clusters the CRDs are discovered in.
+	Kube []Kube `json:"kube,omitempty"`
+	// Stdin reads CRDs from stdin. Only one group can read stdin.
+	Stdin bool `json:"stdin,omitempty"`
+	// Helm are charts that are rendered offline.
+	Helm []HelmChart `json:"helm,omitempty"`
+	// OCI are artifacts whose layers are searched for CRD files.
+	OCI []OCIArtifact `json:"oci,omitempty"`
+	// Minimal only renders the required fields of the samples of this group.
+	Minimal *bool `json:"minimal,omitempty"`
+	// Comments adds the descriptions of the fields to the samples of this group.
+	Comments *bool `json:"comments,omitempty"`
+	// Include are glob patterns of the files to consider in folders, git repositories, archives
+	// and OCI artifacts. Patterns of a source take precedence.
+	Include []string `json:"include,omitempty"`
+	// Exclude are glob patterns of the files to ignore in folders, git repositories, archives and
+	// OCI artifacts. Patterns of a source take precedence.
+	Exclude []string `json:"exclude,omitempty"`
+	// Versions only keeps these CRD versions, like v1. CRDs without any of them are dropped.
+	Versions []string `json:"versions,omitempty"`
+}
+
+// Credentials defines where the credentials of a source are looked up. Username, password and token
+// of a source may also reference environment variables as `${NAME}`.
+type Credentials struct {
+	// Helpers are asked in order.
+	Helpers []string `json:"helpers,omitempty" jsonschema:"enum=netrc|docker|git"`
+	// Exec is a command that prints the credentials as JSON. It's asked before the helpers.
+	Exec *ExecCredentials `json:"exec,omitempty"`
+}
+
+// ExecCredentials is a command that prints `{"username": "", "password": "", "token": ""}` for the
+// URL in $CTY_CREDENTIALS_URL.
+type ExecCredentials struct {
+	// Command is the executable to run.
+	Command string `json:"command"`
+	// Args are the arguments of the command.
+	Args []string `json:"args,omitempty"`
+}
+
+// URLs contains url configuration.
+type URLs struct {
+	// URL of the CRD file.
+	URL string `json:"url"`
+	// Username for basic authentication.
+	Username string `json:"username,omitempty"`
+	// Password for basic authentication.
+	Password string `json:"password,omitempty"`
+	// Token is sent as a bearer token.
+	Token string `json:"token,omitempty"`
+	// Credentials are looked up if username, password and token are empty.
+	Credentials *Credentials `json:"credentials,omitempty"`
+}
+
+// GITUrls contains git url configuration.
+type GITUrls struct {
+	// URL of the repository.
+	URL string `json:"url"`
+	// Username for basic authentication.
+	Username string `json:"username,omitempty"`
+	// Password for basic authentication, or the passphrase of the private key.
+	Password string `json:"password,omitempty"`
+	// Token is sent as a bearer token.
+	Token string `json:"token,omitempty"`
+	// Tag to check out. Deprecated, use ref instead.
+	Tag string `json:"tag,omitempty"`
+	// PrivateKey is the path of a private SSH key.
+	PrivateKey string `json:"privateKey,omitempty"`
+	// UseSSHAgent authenticates with the SSH agent.
+	UseSSHAgent bool `json:"useSSHAgent,omitempty"`
+	// Ref is a branch, tag or commit SHA. It takes precedence over Tag.
+	Ref string `json:"ref,omitempty"`
+	// Paths are glob patterns of the files to consider.
+	Paths []string `json:"paths,omitempty"`
+	// Credentials are looked up if username, password and token are empty.
+	Credentials *Credentials `json:"credentials,omitempty"`
+}
+
+// Archives contains the location and file selection of a tar, tar.gz or zip archive.
+type Archives struct {
+	// Location is a path or a URL.
+	Location string `json:"location"`
+	// Username for basic authentication.
+	Username string `json:"username,omitempty"`
+	// Password for basic authentication.
+	Password string `json:"password,omitempty"`
+	// Token is sent as a bearer token.
+	Token string `json:"token,omitempty"`
+	// Include are glob patterns of the files to consider.
+	Include []string `json:"include,omitempty"`
+	// Exclude are glob patterns of the files to ignore.
+	Exclude []string `json:"exclude,omitempty"`
+	// Credentials are looked up if username, password and token are empty.
+	Credentials *Credentials `json:"credentials,omitempty"`
+}
+
+// Kube discovers the CRDs installed in a cluster. Without a name, group or selector every CRD is used.
+type Kube struct {
+	// Name of a single CRD.
+	Name string `json:"name,omitempty"`
+	// Group only discovers the CRDs of this API group.
+	Group string `json:"group,omitempty"`
+	// Selector is a label selector of the CRDs.
+	Selector string `json:"selector,omitempty"`
+	// Kubeconfig is the path of the kubeconfig. Default is $KUBECONFIG or ~/.kube/config.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Context of the kubeconfig. Default is the current context.
+	Context string `json:"context,omitempty"`
+}
+
+// HelmChart is a chart folder or archive that's rendered offline.
+type HelmChart struct {
+	// Chart is the path of the chart folder or archive.
+	Chart string `json:"chart"`
+	// Values are paths of values files.
+	Values []string `json:"values,omitempty"`
+}
+
+// OCIArtifact is an artifact in a registry. Credentials are read from the docker config.
+type OCIArtifact struct {
+	// Ref is the reference of the artifact, like ghcr.io/org/crds:v1.0.0.
+	Ref string `json:"ref"`
+	// Include are glob patterns of the files to consider.
+	Include []string `json:"include,omitempty"`
+	// Exclude are glob patterns of the files to ignore.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Output is a rendered output of the config.
+type Output struct {
+	// Format of the output.
+	Format string `json:"format" jsonschema:"enum=html|markdown|yaml|schema|validation"`
+	// Path is the file of html output and the folder of every other format.
+	Path string `json:"path"`
+	// Groups only renders these groups. Default is every group.
+	Groups []string `json:"groups,omitempty"`
+	// CSSFile is the path of a CSS file that's injected into html output.
+	CSSFile string `json:"cssFile,omitempty"`
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaIsUpToDate(t *testing.T) {
+	docs, err := ParseDocs(TypesFile)
+	require.NoError(t, err)
+
+	generated, err := GenerateSchema(docs)
+	require.NoError(t, err)
+
+	published, err := os.ReadFile(SchemaFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(generated), string(published), "run go generate ./pkg/config")
+}
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(`apiGroups:
+  - name: operators
+    description: Operator resources
+    minimal: true
+    versions: [v1]
+    include: ["crds/**"]
+    folders: [crds]
+    kube:
+      - group: example.com
+        context: kind-dev
+    helm:
+      - chart: charts/operator
+        values: [values.yaml]
+    oci:
+      - ref: ghcr.io/org/crds:v1.0.0
+    urls:
+      - url: https://example.com/crd.yaml
+        token: ${TOKEN}
+        credentials:
+          helpers: [netrc]
+  - name: stdin
+    stdin: true
+outputs:
+  - format: html
+    path: site/index.html
+    groups: [operators]
+`), "config.yaml")
+	require.NoError(t, err)
+
+	require.Len(t, config.APIGroups, 2)
+
+	group := config.APIGroups[0]
+	assert.Equal(t, "Operator resources", group.Description)
+	require.NotNil(t, group.Minimal)
+	assert.True(t, *group.Minimal)
+	assert.Nil(t, group.Comments)
+	assert.Equal(t, []string{"v1"}, group.Versions)
+	assert.Equal(t, []Kube{{Group: "example.com", Context: "kind-dev"}}, group.Kube)
+	assert.Equal(t, []HelmChart{{Chart: "charts/operator", Values: []string{"values.yaml"}}}, group.Helm)
+	assert.Equal(t, []OCIArtifact{{Ref: "ghcr.io/org/crds:v1.0.0"}}, group.OCI)
+	assert.Equal(t, "${TOKEN}", group.URLs[0].Token)
+	assert.Equal(t, []string{"netrc"}, group.URLs[0].Credentials.Helpers)
+	assert.True(t, config.APIGroups[1].Stdin)
+	assert.Equal(t, []Output{{Format: FormatHTML, Path: "site/index.html", Groups: []string{"operators"}}}, config.Outputs)
+}
+
+func TestParseReportsLines(t *testing.T) {
+	_, err := Parse([]byte(`apiGroups:
+  - name: operators
+    urls:
+      - url: https://example.com/crd.yaml
+        pasword: secret
+    minimal: "yes"
+  - description: no name
+outputs:
+  - format: pdf
+    path: out.pdf
+`), "config.yaml")
+	require.Error(t, err)
+
+	assert.Equal(t, `invalid config file:
+config.yaml:5:9: /apiGroups/0/urls/0: additional properties 'pasword' not allowed
+config.yaml:6:5: /apiGroups/0/minimal: got string, want boolean
+config.yaml:7:5: /apiGroups/1: missing property 'name'
+config.yaml:9:5: /outputs/0/format: value must be one of 'html', 'markdown', 'yaml', 'schema', 'validation'`, err.Error())
+}
+
+func TestParseChecks(t *testing.T) {
+	_, err := Parse([]byte(`apiGroups:
+  - name: a
+    stdin: true
+  - name: b
+    stdin: true
+outputs:
+  - format: yaml
+    path: out
+    groups: [c]
+`), "config.yaml")
+	require.EqualError(t, err, "invalid config file config.yaml: stdin can only be read by one group\noutput out renders unknown group 'c'")
+
+	_, err = Parse([]byte(""), "config.yaml")
+	require.EqualError(t, err, "config file config.yaml is empty")
+
+	_, err = Parse([]byte("apiGroups: [\n"), "config.yaml")
+	require.ErrorContains(t, err, "yaml: line 1")
+}
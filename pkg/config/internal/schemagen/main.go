@@ -0,0 +1,26 @@
+// Command schemagen writes the JSON Schema of the config file next to its Go types. It's run by
+// `go generate` in the config package.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/config"
+)
+
+func main() {
+	docs, err := config.ParseDocs(config.TypesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	schema, err := config.GenerateSchema(docs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(config.SchemaFile, schema, 0o644); err != nil { //nolint:gosec // the schema is published.
+		log.Fatal(err)
+	}
+}
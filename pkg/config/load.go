@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Load reads, validates and decodes the config file.
+func Load(location string) (*RenderConfig, error) {
+	content, err := os.ReadFile(filepath.Clean(location))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return Parse(content, location)
+}
+
+// Parse validates the content against the JSON Schema and decodes it. Every violation is reported
+// with the line and column of the offending value in name.
+func Parse(content []byte, name string) (*RenderConfig, error) {
+	if err := validate(content, name); err != nil {
+		return nil, err
+	}
+
+	config := &RenderConfig{}
+	if err := k8syaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config file: %w", err)
+	}
+
+	if err := config.check(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", name, err)
+	}
+
+	return config, nil
+}
+
+// check validates what the schema can't express.
+func (c *RenderConfig) check() error {
+	var (
+		errs  []error
+		stdin int
+		names = map[string]bool{}
+	)
+
+	for _, group := range c.APIGroups {
+		names[group.Name] = true
+
+		if group.Stdin {
+			stdin++
+		}
+	}
+
+	if stdin > 1 {
+		errs = append(errs, errors.New("stdin can only be read by one group"))
+	}
+
+	for _, output := range c.Outputs {
+		for _, group := range output.Groups {
+			if !names[group] {
+				errs = append(errs, fmt.Errorf("output %s renders unknown group '%s'", output.Path, group))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validate(content []byte, name string) error {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(content, root); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", name, err)
+	}
+
+	if root.Kind == 0 {
+		return fmt.Errorf("config file %s is empty", name)
+	}
+
+	var doc any
+	if err := root.Decode(&doc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", name, err)
+	}
+
+	// the validator expects JSON values.
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to convert config file %s to JSON: %w", name, err)
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("failed to convert config file %s to JSON: %w", name, err)
+	}
+
+	compiled, err := compiledSchema()
+	if err != nil {
+		return err
+	}
+
+	err = compiled.Validate(instance)
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	return violations(validationErr, root, name)
+}
+
+// compiledSchema compiles the schema once.
+var compiledSchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(SchemaID, doc); err != nil {
+		return nil, fmt.Errorf("failed to add config schema: %w", err)
+	}
+
+	compiled, err := compiler.Compile(SchemaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile config schema: %w", err)
+	}
+
+	return compiled, nil
+})
+
+// violation is a schema violation at a position of the config file.
+type violation struct {
+	line, column int
+	path         string
+	message      string
+}
+
+// violations returns an error with a line for every leaf of the validation error, in file order.
+func violations(err *jsonschema.ValidationError, root *yaml.Node, name string) error {
+	printer := message.NewPrinter(language.English)
+
+	var (
+		result []violation
+		walk   func(e *jsonschema.ValidationError)
+	)
+
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+
+			return
+		}
+
+		path := e.InstanceLocation
+		// unknown fields are reported at their key instead of the object that contains them.
+		if additional, ok := e.ErrorKind.(*kind.AdditionalProperties); ok && len(additional.Properties) > 0 {
+			path = append(slices.Clone(path), additional.Properties[0])
+		}
+
+		node := lookup(root, path)
+
+		result = append(result, violation{
+			line:    node.Line,
+			column:  node.Column,
+			path:    "/" + strings.Join(e.InstanceLocation, "/"),
+			message: e.ErrorKind.LocalizedString(printer),
+		})
+	}
+
+	walk(err)
+
+	slices.SortStableFunc(result, func(a, b violation) int {
+		if a.line != b.line {
+			return a.line - b.line
+		}
+
+		return a.column - b.column
+	})
+
+	lines := make([]string, 0, len(result))
+	for _, v := range result {
+		lines = append(lines, fmt.Sprintf("%s:%d:%d: %s: %s", name, v.line, v.column, v.path, v.message))
+	}
+
+	return fmt.Errorf("invalid config file:\n%s", strings.Join(lines, "\n"))
+}
+
+// lookup returns the node at the path of keys and indexes. For the key of a mapping the key node is
+// returned, so the position points at the field name. It stops at the deepest node that exists.
+func lookup(root *yaml.Node, path []string) *yaml.Node {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for i, token := range path {
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == token {
+					next = node.Content[j+1]
+					if i == len(path)-1 {
+						return node.Content[j]
+					}
+
+					break
+				}
+			}
+
+			if next == nil {
+				return node
+			}
+
+			node = next
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return node
+			}
+
+			node = node.Content[index]
+		default:
+			return node
+		}
+	}
+
+	return node
+}
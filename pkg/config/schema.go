@@ -0,0 +1,196 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+//go:generate go run ./internal/schemagen
+
+const (
+	// SchemaID is the published location of the JSON Schema of the config file.
+	SchemaID = "https://raw.githubusercontent.com/Skarlso/crd-to-sample-yaml/main/pkg/config/config.schema.json"
+	// SchemaFile is the name of the generated JSON Schema next to the Go types.
+	SchemaFile = "config.schema.json"
+	// TypesFile is the name of the file that declares the Go types the schema is generated from.
+	TypesFile = "config.go"
+)
+
+//go:embed config.schema.json
+var schema []byte
+
+// Schema returns the JSON Schema of the config file.
+func Schema() []byte {
+	return schema
+}
+
+// GenerateSchema returns the JSON Schema of RenderConfig. docs are the descriptions of the types and
+// their fields, keyed by `Type` and `Type.Field`, as returned by ParseDocs.
+func GenerateSchema(docs map[string]string) ([]byte, error) {
+	g := &schemaGenerator{docs: docs, defs: map[string]any{}}
+
+	root := g.schema(reflect.TypeFor[RenderConfig](), "")
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = SchemaID
+	root["title"] = "crd-to-sample-yaml config file"
+	root["$defs"] = g.defs
+
+	content, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	return append(content, '\n'), nil
+}
+
+// ParseDocs returns the doc comments of the struct types and their fields declared in the Go file,
+// keyed by `Type` and `Type.Field`.
+func ParseDocs(filename string) (map[string]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	docs := map[string]string{}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			doc := typeSpec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+
+			docs[typeSpec.Name.Name] = docText(doc)
+
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			for _, field := range structType.Fields.List {
+				for _, name := range field.Names {
+					docs[typeSpec.Name.Name+"."+name.Name] = docText(field.Doc)
+				}
+			}
+		}
+	}
+
+	return docs, nil
+}
+
+// docText joins the lines of a comment into a single line.
+func docText(doc *ast.CommentGroup) string {
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+type schemaGenerator struct {
+	docs map[string]string
+	defs map[string]any
+}
+
+// schema returns the schema of t. Structs are added to the definitions and referenced. enum is the
+// value of a `jsonschema:"enum=a|b"` tag of the field of t.
+func (g *schemaGenerator) schema(t reflect.Type, enum string) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem(), enum)
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem(), enum)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem(), enum)}
+	case reflect.Struct:
+		if t == reflect.TypeFor[RenderConfig]() {
+			return g.object(t)
+		}
+
+		if _, ok := g.defs[t.Name()]; !ok {
+			// registered before the fields so recursive types terminate.
+			g.defs[t.Name()] = nil
+			g.defs[t.Name()] = g.object(t)
+		}
+
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		s := map[string]any{"type": "string"}
+		if enum != "" {
+			s["enum"] = strings.Split(enum, "|")
+		}
+
+		return s
+	}
+}
+
+// object returns the schema of a struct. Fields without omitempty are required, and unknown fields
+// are rejected to catch typos.
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for field := range t.Fields() {
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		enum := strings.TrimPrefix(field.Tag.Get("jsonschema"), "enum=")
+
+		property := g.schema(field.Type, enum)
+		if doc := g.docs[t.Name()+"."+field.Name]; doc != "" {
+			if _, ref := property["$ref"]; ref {
+				// keywords next to a reference are allowed since draft 2019-09.
+				property = map[string]any{"$ref": property["$ref"], "description": doc}
+			} else {
+				property["description"] = doc
+			}
+		}
+
+		properties[name] = property
+
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+
+	if len(required) > 0 {
+		s["required"] = required
+	}
+
+	if doc := g.docs[t.Name()]; doc != "" {
+		s["description"] = doc
+	}
+
+	return s
+}
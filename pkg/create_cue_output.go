@@ -0,0 +1,263 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	cueIdentifierRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	cuePackageRegex    = regexp.MustCompile(`[^a-z0-9_]`)
+)
+
+// cueRenderer keeps track of the imports required by the rendered constraints.
+type cueRenderer struct {
+	builder strings.Builder
+	imports map[string]struct{}
+}
+
+// RenderCUE writes a CUE definition for the given definition. Required fields are rendered as regular fields
+// and optional fields are marked with `?`. Enums are turned into disjunctions and defaults are marked with `*`.
+func RenderCUE(w io.Writer, def *Definition) error {
+	r := &cueRenderer{imports: map[string]struct{}{}}
+
+	r.comment(def.Description, 0)
+	r.builder.WriteString("#" + def.Kind + ": {\n")
+
+	// apiVersion and kind are constants of every definition, even if the schema doesn't define them.
+	r.comment(def.fieldDescription("apiVersion"), 1)
+	r.builder.WriteString("\tapiVersion: " + cueString(def.APIVersion()) + "\n")
+	r.comment(def.fieldDescription("kind"), 1)
+	r.builder.WriteString("\tkind: " + cueString(def.Kind) + "\n")
+
+	for _, field := range def.Fields {
+		if field.Name == "apiVersion" || field.Name == "kind" {
+			continue
+		}
+
+		r.field(field, 1)
+	}
+
+	r.builder.WriteString("}\n")
+
+	header := strings.Builder{}
+	header.WriteString("// Code generated by cty. DO NOT EDIT.\n\n")
+	header.WriteString("package " + cuePackageName(def.Version) + "\n\n")
+
+	if len(r.imports) > 0 {
+		for _, imp := range []string{"list", "strings"} {
+			if _, ok := r.imports[imp]; ok {
+				header.WriteString("import " + strconv.Quote(imp) + "\n")
+			}
+		}
+
+		header.WriteString("\n")
+	}
+
+	wr := &writer{}
+	wr.write(w, header.String())
+	wr.write(w, r.builder.String())
+
+	if wr.err != nil {
+		return fmt.Errorf("failed to write CUE definition: %w", wr.err)
+	}
+
+	return nil
+}
+
+func (r *cueRenderer) field(field *DefinitionField, indent int) {
+	r.comment(field.Description, indent)
+
+	marker := "?"
+	if field.Required {
+		marker = ""
+	}
+
+	r.builder.WriteString(strings.Repeat("\t", indent) + cueLabel(field.Name) + marker + ": " + r.value(field, indent) + "\n")
+}
+
+// value constructs the complete expression of a field including defaults and nullability.
+func (r *cueRenderer) value(field *DefinitionField, indent int) string {
+	var expr string
+
+	switch {
+	case len(field.Enum) > 0:
+		values := make([]string, 0, len(field.Enum))
+		for _, e := range field.Enum {
+			if field.Default != "" && e == field.Default {
+				e = "*" + e
+			}
+
+			values = append(values, e)
+		}
+
+		expr = strings.Join(values, " | ")
+	default:
+		expr = r.typeExpression(field, indent)
+
+		if field.Default != "" {
+			expr += " | *" + field.Default
+		}
+	}
+
+	if field.Nullable {
+		expr += " | null"
+	}
+
+	return expr
+}
+
+// typeExpression returns the type of the field with all of its constraints.
+func (r *cueRenderer) typeExpression(field *DefinitionField, indent int) string {
+	var constraints []string
+
+	switch field.Type {
+	case typeString:
+		constraints = append(constraints, "string")
+
+		if field.MinLength != nil {
+			r.imports["strings"] = struct{}{}
+			constraints = append(constraints, fmt.Sprintf("strings.MinRunes(%d)", *field.MinLength))
+		}
+
+		if field.MaxLength != nil {
+			r.imports["strings"] = struct{}{}
+			constraints = append(constraints, fmt.Sprintf("strings.MaxRunes(%d)", *field.MaxLength))
+		}
+
+		if field.Pattern != "" {
+			constraints = append(constraints, "=~"+cueRawString(field.Pattern))
+		}
+	case typeInteger, typeNumber:
+		if field.Type == typeInteger {
+			constraints = append(constraints, "int")
+		} else {
+			constraints = append(constraints, "number")
+		}
+
+		if field.Minimum != nil {
+			op := ">="
+			if field.ExclusiveMinimum {
+				op = ">"
+			}
+
+			constraints = append(constraints, op+formatNumber(*field.Minimum))
+		}
+
+		if field.Maximum != nil {
+			op := "<="
+			if field.ExclusiveMaximum {
+				op = "<"
+			}
+
+			constraints = append(constraints, op+formatNumber(*field.Maximum))
+		}
+	case typeBoolean:
+		constraints = append(constraints, "bool")
+	case array:
+		constraints = append(constraints, "[..."+r.value(field.Items, indent)+"]")
+
+		if field.MinItems != nil {
+			r.imports["list"] = struct{}{}
+			constraints = append(constraints, fmt.Sprintf("list.MinItems(%d)", *field.MinItems))
+		}
+
+		if field.MaxItems != nil {
+			r.imports["list"] = struct{}{}
+			constraints = append(constraints, fmt.Sprintf("list.MaxItems(%d)", *field.MaxItems))
+		}
+	case typeObject:
+		constraints = append(constraints, r.structExpression(field, indent))
+	default:
+		constraints = append(constraints, "_")
+	}
+
+	return strings.Join(constraints, " & ")
+}
+
+func (r *cueRenderer) structExpression(field *DefinitionField, indent int) string {
+	if len(field.Fields) == 0 {
+		switch {
+		case field.Values != nil:
+			return "{[string]: " + r.value(field.Values, indent) + "}"
+		case field.Open:
+			return "{...}"
+		default:
+			return "{}"
+		}
+	}
+
+	// Render the nested fields with a separate renderer that shares the imports.
+	nested := &cueRenderer{imports: r.imports}
+	nested.builder.WriteString("{\n")
+
+	for _, f := range field.Fields {
+		nested.field(f, indent+1)
+	}
+
+	if field.Values != nil {
+		nested.builder.WriteString(strings.Repeat("\t", indent+1) + "[string]: " + nested.value(field.Values, indent+1) + "\n")
+	} else if field.Open {
+		nested.builder.WriteString(strings.Repeat("\t", indent+1) + "...\n")
+	}
+
+	nested.builder.WriteString(strings.Repeat("\t", indent) + "}")
+
+	return nested.builder.String()
+}
+
+func (r *cueRenderer) comment(description string, indent int) {
+	if description == "" {
+		return
+	}
+
+	for line := range strings.SplitSeq(strings.TrimSpace(description), "\n") {
+		r.builder.WriteString(strings.TrimRight(strings.Repeat("\t", indent)+"// "+line, " ") + "\n")
+	}
+}
+
+// cueLabel returns a valid CUE label. Labels that aren't valid identifiers are quoted.
+func cueLabel(name string) string {
+	if cueIdentifierRegex.MatchString(name) {
+		return name
+	}
+
+	return cueString(name)
+}
+
+// cueString returns a double-quoted CUE string. JSON escaping is a valid subset of CUE escaping.
+func cueString(s string) string {
+	content, err := json.Marshal(s)
+	if err != nil {
+		return strconv.Quote(s)
+	}
+
+	return string(content)
+}
+
+// cueRawString returns a raw CUE string so regular expressions don't need additional escaping.
+func cueRawString(s string) string {
+	if strings.Contains(s, `"#`) {
+		return cueString(s)
+	}
+
+	return `#"` + s + `"#`
+}
+
+// cuePackageName converts a version name into a valid CUE package name.
+func cuePackageName(version string) string {
+	name := cuePackageRegex.ReplaceAllString(strings.ToLower(version), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "v" + name
+	}
+
+	return name
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
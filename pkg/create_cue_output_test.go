@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+func TestRenderCUE(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "sample_crd.yaml"))
+	require.NoError(t, err)
+
+	crd := &unstructured.Unstructured{}
+	require.NoError(t, yaml.Unmarshal(content, crd))
+	schemaType, err := ExtractSchemaType(crd)
+	require.NoError(t, err)
+
+	definitions := NewDefinitions(schemaType)
+	require.Len(t, definitions, 1)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderCUE(buffer, definitions[0]))
+
+	golden, err := os.ReadFile(filepath.Join("testdata", "sample_crd_golden.cue"))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(golden), buffer.String())
+}
+
+func TestRenderCUEConstraints(t *testing.T) {
+	minimum, maximum := float64(1), float64(10)
+	minLength, minItems := int64(3), int64(1)
+
+	schemaType := &SchemaType{
+		Group: "example.com",
+		Kind:  "Example",
+		Versions: []*CRDVersion{
+			{
+				Name: "v1",
+				Schema: &v1beta1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]v1beta1.JSONSchemaProps{
+						"replicas": {Type: "integer", Minimum: &minimum, Maximum: &maximum, Default: &v1beta1.JSON{Raw: []byte("3")}},
+						"mode": {
+							Type:    "string",
+							Enum:    []v1beta1.JSON{{Raw: []byte(`"a"`)}, {Raw: []byte(`"b"`)}},
+							Default: &v1beta1.JSON{Raw: []byte(`"b"`)},
+						},
+						"name":   {Type: "string", Pattern: `^[a-z]+\d$`, MinLength: &minLength},
+						"tags":   {Type: "array", MinItems: &minItems, Items: &v1beta1.JSONSchemaPropsOrArray{Schema: &v1beta1.JSONSchemaProps{Type: "string"}}},
+						"labels": {Type: "object", AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{Schema: &v1beta1.JSONSchemaProps{Type: "string"}}},
+						"x-id":   {Type: "string", Nullable: true},
+					},
+					Required: []string{"name"},
+				},
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderCUE(buffer, NewDefinitions(schemaType)[0]))
+
+	output := buffer.String()
+	assert.Contains(t, output, "package v1\n")
+	assert.Contains(t, output, "\tapiVersion: \"example.com/v1\"\n\tkind: \"Example\"\n")
+	assert.Contains(t, output, "import \"list\"\nimport \"strings\"\n")
+	assert.Contains(t, output, "\treplicas?: int & >=1 & <=10 | *3\n")
+	assert.Contains(t, output, "\tmode?: \"a\" | *\"b\"\n")
+	assert.Contains(t, output, "\tname: string & strings.MinRunes(3) & =~#\"^[a-z]+\\d$\"#\n")
+	assert.Contains(t, output, "\ttags?: [...string] & list.MinItems(1)\n")
+	assert.Contains(t, output, "\tlabels?: {[string]: string}\n")
+	assert.Contains(t, output, "\t\"x-id\"?: string | null\n")
+}
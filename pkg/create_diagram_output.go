@@ -0,0 +1,344 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DiagramMermaid renders a Mermaid class diagram.
+	DiagramMermaid = "mermaid"
+	// DiagramDot renders a Graphviz digraph.
+	DiagramDot = "dot"
+)
+
+var (
+	diagramIDRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+	// referenceSuffixes are removed from field names to find references to other kinds.
+	referenceSuffixes = []string{"Refs", "Ref", "References", "Reference", "Names", "Name", "Selector"}
+	dotEscaper        = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "{", `\{`, "}", `\}`, "|", `\|`, "<", `\<`, ">", `\>`)
+)
+
+// DiagramOpts configures a schema structure diagram.
+type DiagramOpts struct {
+	// Format is either mermaid or dot.
+	Format string
+	// Depth defines how many levels of nested objects are expanded. 0 means unlimited.
+	Depth int
+}
+
+type diagramField struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+type diagramNode struct {
+	ID     string
+	Title  string
+	Fields []diagramField
+}
+
+type diagramEdge struct {
+	From      string
+	To        string
+	Label     string
+	Reference bool
+}
+
+type diagram struct {
+	nodes []*diagramNode
+	edges []diagramEdge
+	// ids contains every assigned node ID to keep them unique.
+	ids map[string]struct{}
+	// roots contains the node ID of every CRD of the input set.
+	roots map[*SchemaType]string
+	// titles contains the title of every root node, for references to CRDs that aren't part of the diagram.
+	titles map[string]string
+	// kinds contains the known kinds of the input set to find references with.
+	kinds map[string]*SchemaType
+	opts  DiagramOpts
+}
+
+// RenderDiagram writes a single diagram that contains the property tree of the selected version of every CRD.
+// If a CRD doesn't have the given version, or the version is empty, its last version is used.
+// Fields that reference other kinds in the set are connected with an edge.
+func RenderDiagram(w io.Writer, crds []*SchemaType, version string, opts DiagramOpts) error {
+	d := newDiagram(crds, opts)
+
+	for _, crd := range crds {
+		name, def := selectDiagramVersion(crd, version)
+		if def == nil {
+			continue
+		}
+
+		d.addRoot(crd, name, def.Fields)
+	}
+
+	content, err := d.render()
+	if err != nil {
+		return err
+	}
+
+	wr := &writer{}
+	wr.write(w, content)
+
+	if wr.err != nil {
+		return fmt.Errorf("failed to write diagram: %w", wr.err)
+	}
+
+	return nil
+}
+
+// versionDiagram returns a diagram of a single version that can be embedded into other outputs.
+func versionDiagram(crd *SchemaType, def *Definition, crds []*SchemaType, opts DiagramOpts) (string, error) {
+	d := newDiagram(crds, opts)
+	d.addRoot(crd, def.Version, def.Fields)
+
+	return d.render()
+}
+
+func selectDiagramVersion(crd *SchemaType, version string) (string, *Definition) {
+	defs := NewDefinitions(crd)
+	if len(defs) == 0 {
+		return "", nil
+	}
+
+	for _, def := range defs {
+		if def.Version == version {
+			return def.Version, def
+		}
+	}
+
+	last := defs[len(defs)-1]
+
+	return last.Version, last
+}
+
+func newDiagram(crds []*SchemaType, opts DiagramOpts) *diagram {
+	d := &diagram{
+		ids:    map[string]struct{}{},
+		roots:  map[*SchemaType]string{},
+		titles: map[string]string{},
+		kinds:  map[string]*SchemaType{},
+		opts:   opts,
+	}
+
+	// the root IDs are assigned up front, so references can point to CRDs that come later.
+	for _, crd := range crds {
+		name := crd.Kind
+		if crd.Group != "" {
+			name += "." + crd.Group
+		}
+
+		id := d.uniqueID(diagramID(name))
+		d.roots[crd] = id
+		d.titles[id] = name
+
+		if _, ok := d.kinds[strings.ToLower(crd.Kind)]; !ok {
+			d.kinds[strings.ToLower(crd.Kind)] = crd
+		}
+	}
+
+	return d
+}
+
+func (d *diagram) addRoot(crd *SchemaType, version string, fields []*DefinitionField) {
+	id, ok := d.roots[crd]
+	if !ok {
+		id = d.uniqueID(diagramID(crd.Kind))
+	}
+
+	root := &diagramNode{
+		ID:    id,
+		Title: crd.Kind + " " + version,
+	}
+
+	d.addNode(root)
+	d.addFields(root, crd.Kind, fields, 1)
+}
+
+func (d *diagram) addNode(node *diagramNode) {
+	d.nodes = append(d.nodes, node)
+}
+
+// uniqueID returns the ID with a counter suffix if it is already taken, since different names like
+// a-b and a_b map to the same ID.
+func (d *diagram) uniqueID(id string) string {
+	unique := id
+	for i := 2; ; i++ {
+		if _, ok := d.ids[unique]; !ok {
+			break
+		}
+
+		unique = fmt.Sprintf("%s_%d", id, i)
+	}
+
+	d.ids[unique] = struct{}{}
+
+	return unique
+}
+
+// addFields adds the fields to the node and creates child nodes for nested objects until the depth is reached.
+func (d *diagram) addFields(node *diagramNode, kind string, fields []*DefinitionField, level int) {
+	for _, f := range fields {
+		node.Fields = append(node.Fields, diagramField{
+			Name:     f.Name,
+			Type:     diagramType(f),
+			Required: f.Required,
+		})
+
+		if target, ok := d.reference(f, kind); ok {
+			d.edges = append(d.edges, diagramEdge{From: node.ID, To: target, Label: f.Name, Reference: true})
+		}
+
+		nested, label := f, f.Name
+		if f.Type == array && f.Items != nil {
+			nested, label = f.Items, f.Name+"[]"
+		}
+
+		if len(nested.Fields) == 0 || (d.opts.Depth > 0 && level >= d.opts.Depth) {
+			continue
+		}
+
+		child := &diagramNode{
+			ID:    d.uniqueID(node.ID + "_" + diagramID(f.Name)),
+			Title: label,
+		}
+
+		d.addNode(child)
+		d.edges = append(d.edges, diagramEdge{From: node.ID, To: child.ID, Label: label})
+		d.addFields(child, kind, nested.Fields, level+1)
+	}
+}
+
+// reference returns the node of the kind a field refers to. A field is a reference
+// if its name, without common suffixes like Ref or Name, matches a kind in the input set.
+func (d *diagram) reference(f *DefinitionField, kind string) (string, bool) {
+	name := f.Name
+	for _, suffix := range referenceSuffixes {
+		if trimmed, ok := strings.CutSuffix(name, suffix); ok && trimmed != "" {
+			name = trimmed
+
+			break
+		}
+	}
+
+	if name == f.Name {
+		return "", false
+	}
+
+	target, ok := d.kinds[strings.ToLower(name)]
+	if !ok || target.Kind == kind {
+		return "", false
+	}
+
+	return d.roots[target], true
+}
+
+func (d *diagram) render() (string, error) {
+	added := make(map[string]struct{}, len(d.nodes))
+	for _, n := range d.nodes {
+		added[n.ID] = struct{}{}
+	}
+
+	// add nodes for referenced kinds that aren't part of the diagram.
+	for _, e := range d.edges {
+		if _, ok := added[e.To]; !ok {
+			added[e.To] = struct{}{}
+			d.addNode(&diagramNode{ID: e.To, Title: d.titles[e.To]})
+		}
+	}
+
+	switch d.opts.Format {
+	case DiagramMermaid, "":
+		return d.mermaid(), nil
+	case DiagramDot:
+		return d.dot(), nil
+	default:
+		return "", fmt.Errorf("unknown diagram format: %s", d.opts.Format)
+	}
+}
+
+func (d *diagram) mermaid() string {
+	b := &strings.Builder{}
+	b.WriteString("classDiagram\n")
+
+	for _, n := range d.nodes {
+		fmt.Fprintf(b, "    class %s[%q] {\n", n.ID, n.Title)
+
+		for _, f := range n.Fields {
+			line := "+" + f.Name + ": " + f.Type
+			if f.Required {
+				line += " required"
+			}
+
+			b.WriteString("        " + line + "\n")
+		}
+
+		b.WriteString("    }\n")
+	}
+
+	for _, e := range d.edges {
+		arrow := "*--"
+		if e.Reference {
+			arrow = "..>"
+		}
+
+		fmt.Fprintf(b, "    %s %s %s : %s\n", e.From, arrow, e.To, e.Label)
+	}
+
+	return b.String()
+}
+
+func (d *diagram) dot() string {
+	b := &strings.Builder{}
+	b.WriteString("digraph crds {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=record];\n")
+
+	for _, n := range d.nodes {
+		fields := make([]string, 0, len(n.Fields))
+		for _, f := range n.Fields {
+			line := f.Name + ": " + f.Type
+			if f.Required {
+				line += " (required)"
+			}
+
+			fields = append(fields, dotEscaper.Replace(line)+`\l`)
+		}
+
+		fmt.Fprintf(b, "    %s [label=\"{%s|%s}\"];\n", n.ID, dotEscaper.Replace(n.Title), strings.Join(fields, ""))
+	}
+
+	for _, e := range d.edges {
+		style := ""
+		if e.Reference {
+			style = ", style=dashed"
+		}
+
+		fmt.Fprintf(b, "    %s -> %s [label=%q%s];\n", e.From, e.To, e.Label, style)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// diagramType returns a short type description like []string or map[string]object.
+func diagramType(f *DefinitionField) string {
+	switch {
+	case f.Type == array && f.Items != nil:
+		return "[]" + diagramType(f.Items)
+	case f.Type == typeObject && len(f.Fields) == 0 && f.Values != nil:
+		return "map[string]" + diagramType(f.Values)
+	default:
+		return f.Type
+	}
+}
+
+func diagramID(s string) string {
+	return diagramIDRegex.ReplaceAllString(s, "_")
+}
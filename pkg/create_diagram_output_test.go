@@ -0,0 +1,146 @@
+package pkg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+func diagramTestCRDs() []*SchemaType {
+	return []*SchemaType{
+		{
+			Group: "example.com",
+			Kind:  "Cluster",
+			Versions: []*CRDVersion{
+				{
+					Name: "v1",
+					Schema: &v1beta1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]v1beta1.JSONSchemaProps{
+							"spec": {
+								Type: "object",
+								Properties: map[string]v1beta1.JSONSchemaProps{
+									"providerConfigRef": {
+										Type: "object",
+										Properties: map[string]v1beta1.JSONSchemaProps{
+											"name": {Type: "string"},
+										},
+										Required: []string{"name"},
+									},
+									"nodes": {
+										Type: "array",
+										Items: &v1beta1.JSONSchemaPropsOrArray{Schema: &v1beta1.JSONSchemaProps{
+											Type: "object",
+											Properties: map[string]v1beta1.JSONSchemaProps{
+												"size": {Type: "integer"},
+											},
+										}},
+									},
+								},
+							},
+						},
+						Required: []string{"spec"},
+					},
+				},
+			},
+		},
+		{
+			Group: "example.com",
+			Kind:  "ProviderConfig",
+			Versions: []*CRDVersion{
+				{
+					Name: "v1",
+					Schema: &v1beta1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]v1beta1.JSONSchemaProps{
+							"spec": {Type: "object"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRenderDiagramMermaid(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderDiagram(buffer, diagramTestCRDs(), "", DiagramOpts{Format: DiagramMermaid}))
+
+	assert.Equal(t, `classDiagram
+    class Cluster_example_com["Cluster v1"] {
+        +spec: object required
+    }
+    class Cluster_example_com_spec["spec"] {
+        +nodes: []object
+        +providerConfigRef: object
+    }
+    class Cluster_example_com_spec_nodes["nodes[]"] {
+        +size: integer
+    }
+    class Cluster_example_com_spec_providerConfigRef["providerConfigRef"] {
+        +name: string required
+    }
+    class ProviderConfig_example_com["ProviderConfig v1"] {
+        +spec: object
+    }
+    Cluster_example_com *-- Cluster_example_com_spec : spec
+    Cluster_example_com_spec *-- Cluster_example_com_spec_nodes : nodes[]
+    Cluster_example_com_spec ..> ProviderConfig_example_com : providerConfigRef
+    Cluster_example_com_spec *-- Cluster_example_com_spec_providerConfigRef : providerConfigRef
+`, buffer.String())
+}
+
+func TestRenderDiagramDotWithDepth(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderDiagram(buffer, diagramTestCRDs(), "v1", DiagramOpts{Format: DiagramDot, Depth: 1}))
+
+	assert.Equal(t, `digraph crds {
+    rankdir=LR;
+    node [shape=record];
+    Cluster_example_com [label="{Cluster v1|spec: object (required)\l}"];
+    ProviderConfig_example_com [label="{ProviderConfig v1|spec: object\l}"];
+}
+`, buffer.String())
+}
+
+func TestRenderDiagramUniqueIDs(t *testing.T) {
+	object := func(properties ...string) *v1beta1.JSONSchemaProps {
+		props := &v1beta1.JSONSchemaProps{Type: "object", Properties: map[string]v1beta1.JSONSchemaProps{}}
+		for _, p := range properties {
+			props.Properties[p] = v1beta1.JSONSchemaProps{Type: "object", Properties: map[string]v1beta1.JSONSchemaProps{
+				"size": {Type: "integer"},
+			}}
+		}
+
+		return props
+	}
+
+	crds := []*SchemaType{
+		{Group: "a.example.com", Kind: "Cluster", Versions: []*CRDVersion{{Name: "v1", Schema: object("a-b", "a_b")}}},
+		{Group: "b.example.com", Kind: "Cluster", Versions: []*CRDVersion{{Name: "v1", Schema: object()}}},
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderDiagram(buffer, crds, "", DiagramOpts{Format: DiagramDot}))
+
+	assert.Equal(t, `digraph crds {
+    rankdir=LR;
+    node [shape=record];
+    Cluster_a_example_com [label="{Cluster v1|a-b: object\la_b: object\l}"];
+    Cluster_a_example_com_a_b [label="{a-b|size: integer\l}"];
+    Cluster_a_example_com_a_b_2 [label="{a_b|size: integer\l}"];
+    Cluster_b_example_com [label="{Cluster v1|}"];
+    Cluster_a_example_com -> Cluster_a_example_com_a_b [label="a-b"];
+    Cluster_a_example_com -> Cluster_a_example_com_a_b_2 [label="a_b"];
+}
+`, buffer.String())
+}
+
+func TestRenderDiagramUnknownFormat(t *testing.T) {
+	err := RenderDiagram(&bytes.Buffer{}, diagramTestCRDs(), "", DiagramOpts{Format: "svg"})
+	require.EqualError(t, err, "unknown diagram format: svg")
+}
@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"bytes"
+	"cmp"
 	"embed"
 	"fmt"
 	"html/template"
@@ -31,6 +32,7 @@ type Version struct {
 	Description string
 	YAML        string
 	Conditions  []ConditionInfo
+	Diagram     string
 }
 
 // ViewPage is the template for view.html.
@@ -164,8 +166,9 @@ func LoadTemplates() error {
 
 // Group defines a single group with a list of rendered versions.
 type Group struct {
-	Name string
-	Page []ViewPage
+	Name        string
+	Description string
+	Page        []ViewPage
 }
 
 // GroupPage will have a list of groups and inside these groups
@@ -173,6 +176,8 @@ type Group struct {
 type GroupPage struct {
 	Groups    []Group
 	CustomCSS template.CSS
+	Diagrams  bool
+	Releases  []ReleaseLink
 }
 
 type RenderOpts struct {
@@ -180,6 +185,13 @@ type RenderOpts struct {
 	Minimal   bool
 	Random    bool
 	CustomCSS string
+	// Diagram embeds a Mermaid diagram of the schema structure for each version.
+	Diagram      bool
+	DiagramDepth int
+	// Releases adds a version switcher that links to the other releases of a site.
+	Releases []ReleaseLink
+	// Changes adds a badge to the properties that changed since the previous release.
+	Changes *ComparisonReport
 }
 
 // RenderContent creates an HTML website from the CRD content.
@@ -197,7 +209,11 @@ func RenderContent(w io.WriteCloser, crds []*SchemaType, opts RenderOpts) (err e
 	for name, group := range groups {
 		allViews := make([]ViewPage, 0, len(group))
 
+		var description string
+
 		for _, crd := range group {
+			opts := crd.Rendering.Options(opts)
+			description = cmp.Or(description, crd.Rendering.Description)
 			versions := make([]Version, 0)
 			parser := NewParser(crd.Group, crd.Kind, opts.Comments, opts.Minimal, opts.Random)
 
@@ -208,6 +224,12 @@ func RenderContent(w io.WriteCloser, crds []*SchemaType, opts RenderOpts) (err e
 				}
 
 				v.Conditions = crd.Conditions
+				markChanges(&v, crd, opts)
+
+				if err := embedDiagram(&v, crd, version.Schema, crds, opts); err != nil {
+					return err
+				}
+
 				versions = append(versions, v)
 			}
 
@@ -219,6 +241,12 @@ func RenderContent(w io.WriteCloser, crds []*SchemaType, opts RenderOpts) (err e
 				}
 
 				version.Conditions = crd.Conditions
+				markChanges(&version, crd, opts)
+
+				if err := embedDiagram(&version, crd, crd.Validation.Schema, crds, opts); err != nil {
+					return err
+				}
+
 				versions = append(versions, version)
 			} else if len(versions) == 0 {
 				continue
@@ -233,8 +261,9 @@ func RenderContent(w io.WriteCloser, crds []*SchemaType, opts RenderOpts) (err e
 		}
 
 		allGroups = append(allGroups, Group{
-			Name: name,
-			Page: allViews,
+			Name:        name,
+			Description: description,
+			Page:        allViews,
 		})
 	}
 
@@ -243,6 +272,8 @@ func RenderContent(w io.WriteCloser, crds []*SchemaType, opts RenderOpts) (err e
 	index := GroupPage{
 		Groups:    allGroups,
 		CustomCSS: template.CSS(opts.CustomCSS), //nolint:gosec // opts.CustomCSS is escaped and sanitized input
+		Diagrams:  opts.Diagram,
+		Releases:  opts.Releases,
 	}
 
 	if err := t.Execute(w, index); err != nil {
@@ -252,6 +283,25 @@ func RenderContent(w io.WriteCloser, crds []*SchemaType, opts RenderOpts) (err e
 	return nil
 }
 
+// embedDiagram adds a Mermaid diagram of the version's schema if diagrams are enabled.
+func embedDiagram(v *Version, crd *SchemaType, schema *v1beta1.JSONSchemaProps, crds []*SchemaType, opts RenderOpts) error {
+	if !opts.Diagram {
+		return nil
+	}
+
+	diagram, err := versionDiagram(crd, newDefinition(crd, v.Version, schema), crds, DiagramOpts{
+		Format: DiagramMermaid,
+		Depth:  opts.DiagramDepth,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate diagram: %w", err)
+	}
+
+	v.Diagram = diagram
+
+	return nil
+}
+
 func buildUpGroup(crds []*SchemaType) map[string][]*SchemaType {
 	result := map[string][]*SchemaType{}
 
@@ -304,6 +354,71 @@ type Property struct {
 	Required    bool
 	Properties  []*Property
 	Enums       string
+	Constraints []string
+	// Changed is set if the property changed since the previous release.
+	Changed bool
+}
+
+// constraints collects the validation settings of a property in a human-readable form.
+func constraints(v v1beta1.JSONSchemaProps) []string {
+	var result []string
+
+	if v.Format != "" {
+		result = append(result, "format: "+v.Format)
+	}
+
+	if v.Pattern != "" {
+		result = append(result, "pattern: "+v.Pattern)
+	}
+
+	if len(v.Enum) > 0 {
+		enums := make([]string, 0, len(v.Enum))
+		for _, e := range v.Enum {
+			enums = append(enums, string(e.Raw))
+		}
+
+		result = append(result, "enum: "+strings.Join(enums, ", "))
+	}
+
+	if v.Minimum != nil {
+		op := ">="
+		if v.ExclusiveMinimum {
+			op = ">"
+		}
+
+		result = append(result, "value "+op+" "+formatNumber(*v.Minimum))
+	}
+
+	if v.Maximum != nil {
+		op := "<="
+		if v.ExclusiveMaximum {
+			op = "<"
+		}
+
+		result = append(result, "value "+op+" "+formatNumber(*v.Maximum))
+	}
+
+	if v.MinLength != nil {
+		result = append(result, fmt.Sprintf("minLength: %d", *v.MinLength))
+	}
+
+	if v.MaxLength != nil {
+		result = append(result, fmt.Sprintf("maxLength: %d", *v.MaxLength))
+	}
+
+	if v.MinItems != nil {
+		result = append(result, fmt.Sprintf("minItems: %d", *v.MinItems))
+	}
+
+	if v.MaxItems != nil {
+		result = append(result, fmt.Sprintf("maxItems: %d", *v.MaxItems))
+	}
+
+	if v.Nullable {
+		result = append(result, "nullable")
+	}
+
+	return result
 }
 
 // parseCRD takes the properties and constructs a linked list out of the embedded properties that the recursive
@@ -338,7 +453,7 @@ func parseCRD(properties map[string]v1beta1.JSONSchemaProps, version string, min
 		description := v.Description
 		if description == "" && depth == 0 {
 			if k == "apiVersion" {
-				description = fmt.Sprintf("%s/%s", group, version)
+				description = apiVersion(group, version)
 			}
 
 			if k == "kind" {
@@ -361,6 +476,7 @@ func parseCRD(properties map[string]v1beta1.JSONSchemaProps, version string, min
 			Version:     version,
 			Required:    required,
 			Enums:       strings.Join(enums, ", "),
+			Constraints: constraints(v),
 		}
 		if v.Default != nil {
 			p.Default = string(v.Default.Raw)
@@ -382,7 +498,7 @@ func parseCRD(properties map[string]v1beta1.JSONSchemaProps, version string, min
 
 			depth--
 			p.Properties = out
-		case properties[k].Type == array && properties[k].Items.Schema != nil && len(properties[k].Items.Schema.Properties) > 0:
+		case properties[k].Type == array && properties[k].Items != nil && properties[k].Items.Schema != nil && len(properties[k].Items.Schema.Properties) > 0:
 			depth++
 			requiredList = v.Required
 
@@ -0,0 +1,241 @@
+package pkg
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// MarkdownIndex is the name of the navigation file that links to every rendered reference page.
+const MarkdownIndex = "index.md"
+
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// MarkdownPagePath returns the relative location of the reference page of a CRD.
+// Pages are grouped into folders by their rendering group.
+func MarkdownPagePath(crd *SchemaType) string {
+	group := crd.Rendering.Group
+	if group == "" {
+		group = crd.Group
+	}
+
+	return path.Join(sanitizePathSegment(group), sanitizePathSegment(crd.Kind)+".md")
+}
+
+// RenderMarkdown writes a Markdown API reference page for a single CRD. The page contains
+// a section per version with the generated sample, nested property tables and conditions.
+// The related CRDs are used to find references between kinds when diagrams are enabled.
+func RenderMarkdown(w io.Writer, crd *SchemaType, related []*SchemaType, opts RenderOpts) error {
+	opts = crd.Rendering.Options(opts)
+	parser := NewParser(crd.Group, crd.Kind, opts.Comments, opts.Minimal, opts.Random)
+
+	var versions []Version //nolint:prealloc // validation might be added
+
+	for _, version := range crd.Versions {
+		v, err := generate(version.Name, crd.Group, crd.Kind, version.Schema, opts.Minimal, parser)
+		if err != nil {
+			return fmt.Errorf("failed to generate yaml sample: %w", err)
+		}
+
+		if err := embedDiagram(&v, crd, version.Schema, related, opts); err != nil {
+			return err
+		}
+
+		versions = append(versions, v)
+	}
+
+	if len(versions) == 0 && crd.Validation != nil {
+		v, err := generate(crd.Validation.Name, crd.Group, crd.Kind, crd.Validation.Schema, opts.Minimal, parser)
+		if err != nil {
+			return fmt.Errorf("failed to generate yaml sample: %w", err)
+		}
+
+		if err := embedDiagram(&v, crd, crd.Validation.Schema, related, opts); err != nil {
+			return err
+		}
+
+		versions = append(versions, v)
+	}
+
+	page := &strings.Builder{}
+	page.WriteString("# " + crd.Kind + "\n\n")
+	page.WriteString("**Group:** `" + crd.Group + "`\n")
+
+	for _, v := range versions {
+		page.WriteString("\n## " + v.Version + "\n\n")
+		page.WriteString("`apiVersion: " + apiVersion(v.Group, v.Version) + "`\n")
+
+		if v.Description != "" {
+			page.WriteString("\n" + strings.TrimSpace(v.Description) + "\n")
+		}
+
+		page.WriteString("\n### Sample\n\n```yaml\n" + v.YAML + "```\n")
+		page.WriteString("\n### Properties\n")
+		writeMarkdownProperties(page, "", v.Properties)
+
+		if v.Diagram != "" {
+			page.WriteString("\n### Structure\n\n```mermaid\n" + v.Diagram + "```\n")
+		}
+
+		if len(crd.Conditions) > 0 {
+			writeMarkdownConditions(page, crd.Conditions)
+		}
+	}
+
+	wr := &writer{}
+	wr.write(w, page.String())
+
+	if wr.err != nil {
+		return fmt.Errorf("failed to write markdown page: %w", wr.err)
+	}
+
+	return nil
+}
+
+// RenderMarkdownIndex writes a navigation page that links to every CRD page grouped by their rendering group.
+func RenderMarkdownIndex(w io.Writer, crds []*SchemaType) error {
+	groups := map[string][]*SchemaType{}
+	descriptions := map[string]string{}
+
+	for _, crd := range crds {
+		group := crd.Rendering.Group
+		if group == "" {
+			group = crd.Group
+		}
+
+		groups[group] = append(groups[group], crd)
+		descriptions[group] = cmp.Or(descriptions[group], crd.Rendering.Description)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	index := &strings.Builder{}
+	index.WriteString("# API Reference\n")
+
+	for _, name := range names {
+		index.WriteString("\n## " + name + "\n\n")
+
+		if description := descriptions[name]; description != "" {
+			index.WriteString(description + "\n\n")
+		}
+
+		pages := groups[name]
+		sort.SliceStable(pages, func(i, j int) bool {
+			return pages[i].Kind < pages[j].Kind
+		})
+
+		for _, crd := range pages {
+			index.WriteString(fmt.Sprintf("- [%s](%s)\n", crd.Kind, MarkdownPagePath(crd)))
+		}
+	}
+
+	wr := &writer{}
+	wr.write(w, index.String())
+
+	if wr.err != nil {
+		return fmt.Errorf("failed to write markdown index: %w", wr.err)
+	}
+
+	return nil
+}
+
+// writeMarkdownProperties writes a table for the given properties followed by a table for
+// every nested object, so deep schemas stay readable.
+func writeMarkdownProperties(b *strings.Builder, parent string, properties []*Property) {
+	if len(properties) == 0 {
+		return
+	}
+
+	if parent != "" {
+		b.WriteString("\n#### `" + parent + "`\n")
+	}
+
+	b.WriteString("\n| Field | Type | Required | Default | Constraints | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+
+	for _, p := range properties {
+		required := ""
+		if p.Required {
+			required = "yes"
+		}
+
+		def := ""
+		if p.Default != "" {
+			def = "`" + markdownCell(p.Default) + "`"
+		}
+
+		constraintList := make([]string, 0, len(p.Constraints))
+		for _, c := range p.Constraints {
+			constraintList = append(constraintList, "`"+markdownCell(c)+"`")
+		}
+
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s | %s |\n",
+			p.Name,
+			markdownCell(p.Type),
+			required,
+			def,
+			strings.Join(constraintList, "<br>"),
+			markdownCell(strings.TrimSpace(p.Description)),
+		)
+	}
+
+	for _, p := range properties {
+		if len(p.Properties) == 0 {
+			continue
+		}
+
+		name := p.Name
+		if parent != "" {
+			name = parent + "." + p.Name
+		}
+
+		if p.Type == array {
+			name += "[]"
+		}
+
+		writeMarkdownProperties(b, name, p.Properties)
+	}
+}
+
+func writeMarkdownConditions(b *strings.Builder, conditions []ConditionInfo) {
+	b.WriteString("\n### Conditions\n\n")
+	b.WriteString("| Condition | Description | Reasons |\n")
+	b.WriteString("| --- | --- | --- |\n")
+
+	for _, c := range conditions {
+		reasons := make([]string, 0, len(c.Reasons))
+		for _, r := range c.Reasons {
+			reason := "`" + r.Name + "`"
+			if r.Description != "" {
+				reason += ": " + markdownCell(r.Description)
+			}
+
+			reasons = append(reasons, reason)
+		}
+
+		fmt.Fprintf(b, "| `%s` | %s | %s |\n", c.Type, markdownCell(c.Description), strings.Join(reasons, "<br>"))
+	}
+}
+
+func markdownCell(s string) string {
+	return markdownCellReplacer.Replace(s)
+}
+
+// sanitizePathSegment makes sure a name can be used as a single path segment. Names of only dots,
+// like "..", are escaped so they don't refer to a parent folder.
+func sanitizePathSegment(s string) string {
+	s = strings.NewReplacer("/", "_", `\`, "_", " ", "_").Replace(s)
+	if s != "" && strings.Trim(s, ".") == "" {
+		return strings.Repeat("_", len(s))
+	}
+
+	return s
+}
@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "sample_crd.yaml"))
+	require.NoError(t, err)
+
+	crd := &unstructured.Unstructured{}
+	require.NoError(t, yaml.Unmarshal(content, crd))
+	schemaType, err := ExtractSchemaType(crd)
+	require.NoError(t, err)
+
+	schemaType.Conditions = []ConditionInfo{
+		{
+			Type:        "Ready",
+			Description: "Ready signals | done.",
+			Reasons:     []ReasonInfo{{Name: "Succeeded", Description: "All good."}},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderMarkdown(buffer, schemaType, nil, RenderOpts{Random: true}))
+
+	golden, err := os.ReadFile(filepath.Join("testdata", "sample_crd_golden.md"))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(golden), buffer.String())
+}
+
+func TestMarkdownPagePath(t *testing.T) {
+	tests := []struct {
+		name string
+		crd  *SchemaType
+		want string
+	}{
+		{name: "group", crd: &SchemaType{Kind: "Alpha", Group: "a.example.com"}, want: "a.example.com/Alpha.md"},
+		{name: "separators", crd: &SchemaType{Kind: "Alpha", Group: "a/b c"}, want: "a_b_c/Alpha.md"},
+		{name: "parent folder", crd: &SchemaType{Kind: "Alpha", Rendering: Rendering{Group: ".."}}, want: "__/Alpha.md"},
+		{name: "current folder", crd: &SchemaType{Kind: "Alpha", Group: "."}, want: "_/Alpha.md"},
+		{name: "dots in kind", crd: &SchemaType{Kind: "..", Group: "a.example.com"}, want: "a.example.com/__.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MarkdownPagePath(tt.crd))
+		})
+	}
+}
+
+func TestRenderMarkdownIndex(t *testing.T) {
+	crds := []*SchemaType{
+		{Kind: "Zeta", Group: "b.example.com"},
+		{Kind: "Alpha", Group: "b.example.com"},
+		{Kind: "Beta", Group: "a.example.com", Rendering: Rendering{Group: "com.aws.services"}},
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderMarkdownIndex(buffer, crds))
+
+	assert.Equal(t, `# API Reference
+
+## b.example.com
+
+- [Alpha](b.example.com/Alpha.md)
+- [Zeta](b.example.com/Zeta.md)
+
+## com.aws.services
+
+- [Beta](com.aws.services/Beta.md)
+`, buffer.String())
+}
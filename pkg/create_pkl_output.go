@@ -0,0 +1,443 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var pklIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+var pklKeywords = []string{
+	"abstract", "amends", "as", "class", "const", "else", "extends", "external", "false", "fixed", "for",
+	"function", "hidden", "if", "import", "in", "is", "let", "local", "module", "new", "nothing", "null",
+	"open", "out", "outer", "read", "super", "this", "throw", "trace", "true", "typealias", "unknown", "when",
+}
+
+// pklRenderer collects the classes that nested objects are translated into.
+type pklRenderer struct {
+	classes    []string
+	classNames map[string]struct{}
+}
+
+// RenderPkl writes a Pkl module for the given definition. Nested objects are rendered as classes
+// which are named after the path of the property they belong to.
+func RenderPkl(w io.Writer, def *Definition) error {
+	r := &pklRenderer{classNames: map[string]struct{}{}}
+	body := strings.Builder{}
+
+	// apiVersion and kind are constants of every module, even if the schema doesn't define them.
+	body.WriteString("\n" + pklComment(def.fieldDescription("apiVersion"), ""))
+	body.WriteString("fixed apiVersion: String = " + pklString(def.APIVersion()) + "\n")
+	body.WriteString("\n" + pklComment(def.fieldDescription("kind"), ""))
+	body.WriteString("fixed kind: String = " + pklString(def.Kind) + "\n")
+
+	for _, field := range def.Fields {
+		if field.Name == "apiVersion" || field.Name == "kind" {
+			continue
+		}
+
+		body.WriteString("\n")
+		body.WriteString(r.property(field, "", ""))
+	}
+
+	out := strings.Builder{}
+	out.WriteString("// Code generated by cty. DO NOT EDIT.\n\n")
+	out.WriteString(pklComment(def.Description, ""))
+	out.WriteString("module " + pklModuleName(def) + "\n")
+	out.WriteString(body.String())
+
+	for _, class := range r.classes {
+		out.WriteString("\n" + class)
+	}
+
+	wr := &writer{}
+	wr.write(w, out.String())
+
+	if wr.err != nil {
+		return fmt.Errorf("failed to write Pkl module: %w", wr.err)
+	}
+
+	return nil
+}
+
+// property renders a single property. The parent is used to construct unique class names.
+func (r *pklRenderer) property(field *DefinitionField, parent, indent string) string {
+	typ := r.typeExpression(field, parent+pklClassSegment(field.Name))
+	if !field.Required || field.Nullable {
+		typ = pklOptional(typ)
+	}
+
+	line := indent + pklIdentifier(field.Name) + ": " + typ
+
+	if value, ok := pklDefault(field, indent); ok {
+		line += " = " + value
+	}
+
+	return pklComment(field.Description, indent) + line + "\n"
+}
+
+// typeExpression returns the Pkl type of the field together with its constraints.
+func (r *pklRenderer) typeExpression(field *DefinitionField, className string) string {
+	if len(field.Enum) > 0 {
+		return pklEnum(field)
+	}
+
+	var constraints []string
+
+	switch field.Type {
+	case typeString:
+		if field.MinLength != nil {
+			constraints = append(constraints, fmt.Sprintf("length >= %d", *field.MinLength))
+		}
+
+		if field.MaxLength != nil {
+			constraints = append(constraints, fmt.Sprintf("length <= %d", *field.MaxLength))
+		}
+
+		if field.Pattern != "" {
+			constraints = append(constraints, "matches(Regex("+pklRawString(field.Pattern)+"))")
+		}
+
+		return pklConstrained("String", constraints)
+	case typeInteger, typeNumber:
+		if field.Minimum != nil {
+			op := ">="
+			if field.ExclusiveMinimum {
+				op = ">"
+			}
+
+			constraints = append(constraints, "this "+op+" "+formatNumber(*field.Minimum))
+		}
+
+		if field.Maximum != nil {
+			op := "<="
+			if field.ExclusiveMaximum {
+				op = "<"
+			}
+
+			constraints = append(constraints, "this "+op+" "+formatNumber(*field.Maximum))
+		}
+
+		if field.Type == typeInteger {
+			return pklConstrained("Int", constraints)
+		}
+
+		return pklConstrained("Number", constraints)
+	case typeBoolean:
+		return "Boolean"
+	case array:
+		if field.MinItems != nil {
+			constraints = append(constraints, fmt.Sprintf("length >= %d", *field.MinItems))
+		}
+
+		if field.MaxItems != nil {
+			constraints = append(constraints, fmt.Sprintf("length <= %d", *field.MaxItems))
+		}
+
+		item := r.typeExpression(field.Items, className+"Item")
+		if field.Items.Nullable {
+			item = pklOptional(item)
+		}
+
+		return pklConstrained("Listing<"+item+">", constraints)
+	case typeObject:
+		if len(field.Fields) == 0 {
+			if field.Values != nil {
+				value := r.typeExpression(field.Values, className+"Value")
+				if field.Values.Nullable {
+					value = pklOptional(value)
+				}
+
+				return "Mapping<String, " + value + ">"
+			}
+
+			return "Mapping<String, Any>"
+		}
+
+		return r.class(field, className)
+	default:
+		return "Any"
+	}
+}
+
+// class renders a nested object as a class and returns its name.
+func (r *pklRenderer) class(field *DefinitionField, className string) string {
+	name := className
+	for i := 2; ; i++ {
+		if _, ok := r.classNames[name]; !ok {
+			break
+		}
+
+		name = className + strconv.Itoa(i)
+	}
+
+	r.classNames[name] = struct{}{}
+
+	// reserve the index so classes are written in the order in which they are encountered.
+	index := len(r.classes)
+	r.classes = append(r.classes, "")
+
+	class := strings.Builder{}
+	class.WriteString(pklComment(field.Description, ""))
+	class.WriteString("class " + name + " {\n")
+
+	for i, f := range field.Fields {
+		if i > 0 {
+			class.WriteString("\n")
+		}
+
+		class.WriteString(r.property(f, name, "  "))
+	}
+
+	class.WriteString("}\n")
+	r.classes[index] = class.String()
+
+	return name
+}
+
+// pklEnum renders an enum as a union of string literal types or as a constrained type for other values.
+func pklEnum(field *DefinitionField) string {
+	values := make([]string, 0, len(field.Enum))
+	strs := true
+
+	for _, e := range field.Enum {
+		var s string
+		if err := json.Unmarshal([]byte(e), &s); err != nil {
+			strs = false
+
+			break
+		}
+
+		values = append(values, pklString(s))
+	}
+
+	if strs {
+		return "(" + strings.Join(values, "|") + ")"
+	}
+
+	base := "Any"
+
+	switch field.Type {
+	case typeInteger:
+		base = "Int"
+	case typeNumber:
+		base = "Number"
+	case typeBoolean:
+		base = "Boolean"
+	}
+
+	return base + "(List(" + strings.Join(field.Enum, ", ") + ").contains(this))"
+}
+
+// pklDefault converts the raw JSON default of a field into a Pkl literal.
+func pklDefault(field *DefinitionField, indent string) (string, bool) {
+	if field.Default == "" {
+		return "", false
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(field.Default), &value); err != nil {
+		return "", false
+	}
+
+	return pklValue(field, value, indent), true
+}
+
+// pklValue renders a JSON value as a Pkl literal of the given field, which is nil for values
+// without a schema. Arrays are rendered as listings and objects as mappings, or as amended
+// classes if the field is rendered as a class.
+func pklValue(field *DefinitionField, value any, indent string) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return pklString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return formatNumber(v)
+	case []any:
+		var items *DefinitionField
+		if field != nil {
+			items = field.Items
+		}
+
+		members := make([]string, 0, len(v))
+		for _, e := range v {
+			members = append(members, pklValue(items, e, indent+"  "))
+		}
+
+		return pklObject("new Listing", members, indent)
+	case map[string]any:
+		if field != nil && field.Type == typeObject && len(field.Fields) > 0 {
+			members := make([]string, 0, len(v))
+
+			for _, f := range field.Fields {
+				// keys that aren't properties of the class are pruned, like the API server does.
+				if e, ok := v[f.Name]; ok {
+					members = append(members, pklIdentifier(f.Name)+" = "+pklValue(f, e, indent+"  "))
+				}
+			}
+
+			return pklObject("new", members, indent)
+		}
+
+		var values *DefinitionField
+		if field != nil {
+			values = field.Values
+		}
+
+		members := make([]string, 0, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			members = append(members, "["+pklString(k)+"] = "+pklValue(values, v[k], indent+"  "))
+		}
+
+		return pklObject("new Mapping", members, indent)
+	default:
+		return "null"
+	}
+}
+
+// pklObject renders an object literal with a member per line.
+func pklObject(prefix string, members []string, indent string) string {
+	if len(members) == 0 {
+		return prefix + " {}"
+	}
+
+	b := strings.Builder{}
+	b.WriteString(prefix + " {\n")
+
+	for _, m := range members {
+		b.WriteString(indent + "  " + m + "\n")
+	}
+
+	b.WriteString(indent + "}")
+
+	return b.String()
+}
+
+func pklConstrained(typ string, constraints []string) string {
+	if len(constraints) == 0 {
+		return typ
+	}
+
+	return typ + "(" + strings.Join(constraints, ", ") + ")"
+}
+
+func pklOptional(typ string) string {
+	if strings.HasSuffix(typ, "?") {
+		return typ
+	}
+
+	return typ + "?"
+}
+
+func pklComment(description, indent string) string {
+	if description == "" {
+		return ""
+	}
+
+	comment := strings.Builder{}
+	for line := range strings.SplitSeq(strings.TrimSpace(description), "\n") {
+		comment.WriteString(strings.TrimRight(indent+"/// "+line, " ") + "\n")
+	}
+
+	return comment.String()
+}
+
+// pklIdentifier returns the name as is or quoted with backticks if it isn't a valid identifier.
+func pklIdentifier(name string) string {
+	if pklIdentifierRegex.MatchString(name) && !slices.Contains(pklKeywords, name) {
+		return name
+	}
+
+	return "`" + name + "`"
+}
+
+// pklClassSegment converts a property name into a part of a class name.
+func pklClassSegment(name string) string {
+	var b strings.Builder
+
+	upper := true
+
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+
+			continue
+		}
+
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// pklModuleName creates a module name from the group, version and kind.
+func pklModuleName(def *Definition) string {
+	var segments []string
+	if def.Group != "" {
+		segments = append(segments, strings.Split(def.Group, ".")...)
+	}
+
+	segments = append(segments, def.Version, def.Kind)
+
+	for i, s := range segments {
+		segments[i] = pklIdentifier(s)
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// pklString returns a double-quoted Pkl string.
+func pklString(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+
+	for _, c := range s {
+		switch c {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if unicode.IsPrint(c) {
+				b.WriteRune(c)
+			} else {
+				_, _ = fmt.Fprintf(&b, `\u{%x}`, c)
+			}
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+// pklRawString returns a custom delimited Pkl string so regular expressions don't need additional escaping.
+func pklRawString(s string) string {
+	if strings.Contains(s, `"#`) {
+		return pklString(s)
+	}
+
+	return `#"` + s + `"#`
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+func TestRenderPkl(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "sample_crd.yaml"))
+	require.NoError(t, err)
+
+	crd := &unstructured.Unstructured{}
+	require.NoError(t, yaml.Unmarshal(content, crd))
+	schemaType, err := ExtractSchemaType(crd)
+	require.NoError(t, err)
+
+	definitions := NewDefinitions(schemaType)
+	require.Len(t, definitions, 1)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderPkl(buffer, definitions[0]))
+
+	golden, err := os.ReadFile(filepath.Join("testdata", "sample_crd_golden.pkl"))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(golden), buffer.String())
+}
+
+func TestRenderPklConstraints(t *testing.T) {
+	minimum := float64(1)
+	maxLength := int64(5)
+
+	schemaType := &SchemaType{
+		Group: "cluster.x-k8s.io",
+		Kind:  "Example",
+		Versions: []*CRDVersion{
+			{
+				Name: "v1",
+				Schema: &v1beta1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]v1beta1.JSONSchemaProps{
+						"replicas": {Type: "integer", Minimum: &minimum, Default: &v1beta1.JSON{Raw: []byte("3")}},
+						"mode": {
+							Type: "string",
+							Enum: []v1beta1.JSON{{Raw: []byte(`"a"`)}, {Raw: []byte(`"b"`)}},
+						},
+						"name": {Type: "string", Pattern: `^[a-z]+$`, MaxLength: &maxLength},
+						"for":  {Type: "boolean"},
+						"template": {
+							Type: "object",
+							Properties: map[string]v1beta1.JSONSchemaProps{
+								"image": {Type: "string"},
+							},
+							Required: []string{"image"},
+						},
+					},
+					Required: []string{"name", "template"},
+				},
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderPkl(buffer, NewDefinitions(schemaType)[0]))
+
+	output := buffer.String()
+	assert.Contains(t, output, "module cluster.`x-k8s`.io.v1.Example\n")
+	assert.Contains(t, output, "replicas: Int(this >= 1)? = 3\n")
+	assert.Contains(t, output, "mode: (\"a\"|\"b\")?\n")
+	assert.Contains(t, output, "name: String(length <= 5, matches(Regex(#\"^[a-z]+$\"#)))\n")
+	assert.Contains(t, output, "`for`: Boolean?\n")
+	assert.Contains(t, output, "template: Template\n")
+	assert.Contains(t, output, "class Template {\n  image: String\n}\n")
+}
+
+func TestRenderPklDefaults(t *testing.T) {
+	schemaType := &SchemaType{
+		Kind: "Example",
+		Versions: []*CRDVersion{
+			{
+				Name: "v1",
+				Schema: &v1beta1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]v1beta1.JSONSchemaProps{
+						"tags": {
+							Type:    "array",
+							Items:   &v1beta1.JSONSchemaPropsOrArray{Schema: &v1beta1.JSONSchemaProps{Type: "string"}},
+							Default: &v1beta1.JSON{Raw: []byte(`["a","b"]`)},
+						},
+						"labels": {
+							Type:                 "object",
+							AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{Schema: &v1beta1.JSONSchemaProps{Type: "string"}},
+							Default:              &v1beta1.JSON{Raw: []byte(`{"team":"a","app":"b"}`)},
+						},
+						"empty": {
+							Type:    "array",
+							Items:   &v1beta1.JSONSchemaPropsOrArray{Schema: &v1beta1.JSONSchemaProps{Type: "string"}},
+							Default: &v1beta1.JSON{Raw: []byte(`[]`)},
+						},
+						"template": {
+							Type: "object",
+							Properties: map[string]v1beta1.JSONSchemaProps{
+								"image": {Type: "string"},
+								"ports": {Type: "array", Items: &v1beta1.JSONSchemaPropsOrArray{Schema: &v1beta1.JSONSchemaProps{Type: "integer"}}},
+							},
+							Default: &v1beta1.JSON{Raw: []byte(`{"image":"nginx","ports":[80],"unknown":true}`)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderPkl(buffer, NewDefinitions(schemaType)[0]))
+
+	output := buffer.String()
+	assert.Contains(t, output, "fixed apiVersion: String = \"v1\"\n")
+	assert.Contains(t, output, "fixed kind: String = \"Example\"\n")
+	assert.Contains(t, output, "tags: Listing<String>? = new Listing {\n  \"a\"\n  \"b\"\n}\n")
+	assert.Contains(t, output, "labels: Mapping<String, String>? = new Mapping {\n  [\"app\"] = \"b\"\n  [\"team\"] = \"a\"\n}\n")
+	assert.Contains(t, output, "empty: Listing<String>? = new Listing {}\n")
+	assert.Contains(t, output, "template: Template? = new {\n  image = \"nginx\"\n  ports = new Listing {\n    80\n  }\n}\n")
+}
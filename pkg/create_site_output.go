@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// SiteIndex is the name of the page of every release and of the version switcher at the root of a site.
+const SiteIndex = "index.html"
+
+// ReleaseLink is an entry of the version switcher of a site.
+type ReleaseLink struct {
+	Name    string
+	URL     string
+	Current bool
+}
+
+// SiteIndexPage is the template for site_index.html.
+type SiteIndexPage struct {
+	Releases []ReleaseLink
+}
+
+// SitePagePath returns the relative location of the page of a release.
+func SitePagePath(release string) string {
+	return path.Join(sanitizePathSegment(release), SiteIndex)
+}
+
+// ReleaseLinks returns the version switcher entries for the page of the current release, newest release
+// first. The releases are expected oldest first. If current is empty, the links are relative to the root
+// of the site instead of the page of a release.
+func ReleaseLinks(releases []string, current string) []ReleaseLink {
+	links := make([]ReleaseLink, 0, len(releases))
+
+	for _, release := range slices.Backward(releases) {
+		url := SitePagePath(release)
+		if current != "" {
+			url = "../" + url
+		}
+
+		links = append(links, ReleaseLink{Name: release, URL: url, Current: release == current})
+	}
+
+	return links
+}
+
+// RenderSiteIndex writes the root page of a site that links to the page of every release.
+func RenderSiteIndex(w io.Writer, releases []ReleaseLink) error {
+	t := templates["site_index.html"]
+
+	if err := t.Execute(w, SiteIndexPage{Releases: releases}); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// markChanges flags the properties of the version that changed according to the comparison report.
+func markChanges(v *Version, crd *SchemaType, opts RenderOpts) {
+	if opts.Changes == nil {
+		return
+	}
+
+	changed := changedProperties(opts.Changes, crd.Group, crd.Kind, v.Version)
+	if len(changed) == 0 {
+		return
+	}
+
+	markChanged(v.Properties, "spec", changed)
+}
+
+// changedProperties returns the paths of the properties of a version that changed, and of their parents.
+// Paths use the format of the schema validator, like spec.properties.spec.properties.replicas.
+func changedProperties(report *ComparisonReport, group, kind, version string) map[string]bool {
+	changed := map[string]bool{}
+
+	for _, kindReport := range report.Kinds {
+		if kindReport.Group != group || kindReport.Kind != kind {
+			continue
+		}
+
+		for _, versionReport := range kindReport.Versions {
+			if versionReport.ToVersion != version {
+				continue
+			}
+
+			for _, change := range versionReport.Changes {
+				p := changePropertyPath(change)
+
+				for {
+					changed[p] = true
+
+					i := strings.LastIndex(p, ".properties.")
+					if i < 0 {
+						break
+					}
+
+					p = p[:i]
+				}
+			}
+		}
+	}
+
+	return changed
+}
+
+// changePropertyPath returns the path of the property a change belongs to, without a trailing attribute
+// like .type or .minimum. A changed required list belongs to the property that is or was required.
+func changePropertyPath(change Change) string {
+	const properties = ".properties."
+
+	property, attribute := change.Path, ""
+
+	name := 0
+	if i := strings.LastIndex(change.Path, properties); i >= 0 {
+		name = i + len(properties)
+	}
+
+	if i := strings.Index(change.Path[name:], "."); i >= 0 {
+		property, attribute = change.Path[:name+i], change.Path[name+i+1:]
+	}
+
+	if attribute == "required" {
+		return property + properties + cmp.Or(change.NewValue, change.OldValue)
+	}
+
+	return property
+}
+
+func markChanged(properties []*Property, parent string, changed map[string]bool) {
+	for _, p := range properties {
+		p.Changed = changed[parent+".properties."+p.Name]
+
+		markChanged(p.Properties, parent+".properties."+p.Name, changed)
+	}
+}
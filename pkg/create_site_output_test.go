@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+func siteSchemaType(required ...string) *SchemaType {
+	return &SchemaType{
+		Group: "example.com",
+		Kind:  "Foo",
+		Versions: []*CRDVersion{{Name: "v1", Schema: &v1beta1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]v1beta1.JSONSchemaProps{
+				"spec": {
+					Type:     "object",
+					Required: required,
+					Properties: map[string]v1beta1.JSONSchemaProps{
+						"name":     {Type: "string"},
+						"replicas": {Type: "integer"},
+					},
+				},
+			},
+		}}},
+	}
+}
+
+func TestReleaseLinks(t *testing.T) {
+	releases := []string{"v1.0.0", "api/v1.1.0"}
+
+	assert.Equal(t, []ReleaseLink{
+		{Name: "api/v1.1.0", URL: "api_v1.1.0/index.html"},
+		{Name: "v1.0.0", URL: "v1.0.0/index.html"},
+	}, ReleaseLinks(releases, ""))
+
+	assert.Equal(t, []ReleaseLink{
+		{Name: "api/v1.1.0", URL: "../api_v1.1.0/index.html"},
+		{Name: "v1.0.0", URL: "../v1.0.0/index.html", Current: true},
+	}, ReleaseLinks(releases, "v1.0.0"))
+}
+
+func TestRenderSiteIndex(t *testing.T) {
+	require.NoError(t, LoadTemplates())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, RenderSiteIndex(buf, ReleaseLinks([]string{"v1.0.0", "v1.1.0"}, "")))
+
+	assert.Contains(t, buf.String(), `<li><a href="v1.1.0/index.html">v1.1.0</a></li>`)
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("v1.1.0")), bytes.Index(buf.Bytes(), []byte("v1.0.0")))
+}
+
+func TestChangePropertyPath(t *testing.T) {
+	testCases := []struct {
+		change Change
+		path   string
+	}{
+		{change: Change{Path: "spec.properties.spec.properties.name"}, path: "spec.properties.spec.properties.name"},
+		{change: Change{Path: "spec.properties.spec.properties.name.type"}, path: "spec.properties.spec.properties.name"},
+		{change: Change{Path: "spec.properties.spec.properties.type"}, path: "spec.properties.spec.properties.type"},
+		{change: Change{Path: "spec.properties.spec.required", NewValue: "name"}, path: "spec.properties.spec.properties.name"},
+		{change: Change{Path: "spec.properties.spec.required", OldValue: "name"}, path: "spec.properties.spec.properties.name"},
+		{change: Change{Path: "spec.required", NewValue: "spec"}, path: "spec.properties.spec"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.change.Path, func(t *testing.T) {
+			assert.Equal(t, tc.path, changePropertyPath(tc.change))
+		})
+	}
+}
+
+func TestRenderContentWithChanges(t *testing.T) {
+	require.NoError(t, LoadTemplates())
+
+	base := siteSchemaType()
+	head := siteSchemaType("name")
+
+	report, err := NewSchemaValidator().CompareSchemaTypes("v1.0.0", []*SchemaType{base}, "v1.1.0", []*SchemaType{head})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, RenderContent(&WriteNoOpCloser{w: buf}, []*SchemaType{head}, RenderOpts{
+		Releases: ReleaseLinks([]string{"v1.0.0", "v1.1.0"}, "v1.1.0"),
+		Changes:  report,
+	}))
+
+	output := buf.String()
+	assert.Contains(t, output, `<option value="../v1.1.0/index.html" selected>v1.1.0</option>`)
+	assert.Contains(t, output, `<option value="../v1.0.0/index.html" >v1.0.0</option>`)
+	// spec and name are changed, replicas isn't.
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte(`<span class="property-type property-changed">Changed</span>`)))
+
+	buf.Reset()
+	require.NoError(t, RenderContent(&WriteNoOpCloser{w: buf}, []*SchemaType{head}, RenderOpts{}))
+	assert.NotContains(t, buf.String(), "property-changed\">")
+	assert.NotContains(t, buf.String(), "release-switcher\">")
+}
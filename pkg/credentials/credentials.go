@@ -0,0 +1,115 @@
+// Package credentials resolves the credentials of remote sources without keeping secrets in config
+// files. Literal values may reference environment variables, and missing credentials are looked up
+// in .netrc, docker config files, git credential helpers or the output of a command.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+)
+
+const (
+	// HelperNetrc looks up the host in the .netrc file.
+	HelperNetrc = "netrc"
+	// HelperDocker looks up the host in the docker config file and its credential helpers.
+	HelperDocker = "docker"
+	// HelperGit asks the configured git credential helpers.
+	HelperGit = "git"
+)
+
+// Credentials authenticate a request with a username and password or with a bearer token.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// Empty returns true if no credential is set.
+func (c Credentials) Empty() bool {
+	return c.Username == "" && c.Password == "" && c.Token == ""
+}
+
+// Provider looks up the credentials of a URL. It returns nil if it has none for the URL.
+type Provider interface {
+	Lookup(u *url.URL) (*Credentials, error)
+}
+
+// NewProviders returns the providers of the helper names, in order.
+func NewProviders(helpers []string) ([]Provider, error) {
+	providers := make([]Provider, 0, len(helpers))
+
+	for _, helper := range helpers {
+		switch helper {
+		case HelperNetrc:
+			providers = append(providers, &Netrc{})
+		case HelperDocker:
+			providers = append(providers, &Docker{})
+		case HelperGit:
+			providers = append(providers, &GitHelper{})
+		default:
+			return nil, fmt.Errorf("unknown credential helper '%s', supported helpers are %s, %s and %s",
+				helper, HelperNetrc, HelperDocker, HelperGit)
+		}
+	}
+
+	return providers, nil
+}
+
+// Resolve returns the explicit credentials with their environment variables expanded. If they are
+// empty, the providers are asked in order and the first credentials found are returned. Providers are
+// only asked for http and https URLs.
+func Resolve(rawURL string, explicit Credentials, providers ...Provider) (Credentials, error) {
+	var err error
+
+	for _, value := range []*string{&explicit.Username, &explicit.Password, &explicit.Token} {
+		if *value, err = Expand(*value); err != nil {
+			return Credentials{}, err
+		}
+	}
+
+	if !explicit.Empty() || len(providers) == 0 {
+		return explicit, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return explicit, nil //nolint:nilerr // only remote URLs have credentials.
+	}
+
+	for _, provider := range providers {
+		creds, err := provider.Lookup(u)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed to look up credentials for %s: %w", RedactURL(rawURL), err)
+		}
+
+		if creds != nil && !creds.Empty() {
+			return *creds, nil
+		}
+	}
+
+	return explicit, nil
+}
+
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Expand replaces every `${NAME}` in s with the value of the environment variable. Other uses of `$`
+// are kept as they are. It fails if a referenced variable isn't set.
+func Expand(s string) (string, error) {
+	var missing []error
+
+	expanded := envPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := envPattern.FindStringSubmatch(match)[1]
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, fmt.Errorf("environment variable %s is not set", name))
+		}
+
+		return value
+	})
+
+	return expanded, errors.Join(missing...)
+}
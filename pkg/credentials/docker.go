@@ -0,0 +1,49 @@
+package credentials
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Docker looks up credentials of the host in the docker config file, $DOCKER_CONFIG/config.json or
+// ~/.docker/config.json, including its credential helpers. It's the same lookup OCI sources use.
+type Docker struct {
+	// Keychain is used instead of the default keychain if set.
+	Keychain authn.Keychain
+}
+
+// Lookup returns the credentials of the host. A registry token is used as a bearer token.
+func (d *Docker) Lookup(u *url.URL) (*Credentials, error) {
+	keychain := d.Keychain
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+
+	registry, err := name.NewRegistry(u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse registry %s: %w", u.Host, err)
+	}
+
+	auth, err := keychain.Resolve(registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve docker credentials: %w", err)
+	}
+
+	if auth == authn.Anonymous {
+		return nil, nil
+	}
+
+	config, err := auth.Authorization()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get docker credentials: %w", err)
+	}
+
+	return &Credentials{
+		Username: config.Username,
+		Password: config.Password,
+		Token:    config.RegistryToken,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// URLEnv is the environment variable that contains the URL for the command of an Exec provider.
+const URLEnv = "CTY_CREDENTIALS_URL"
+
+// Exec runs a command that prints the credentials of the URL in $CTY_CREDENTIALS_URL as JSON:
+//
+//	{"username": "...", "password": "...", "token": "..."}
+type Exec struct {
+	Command string
+	Args    []string
+}
+
+type execOutput struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// Lookup runs the command and returns the credentials it printed.
+func (e *Exec) Lookup(u *url.URL) (*Credentials, error) {
+	if e.Command == "" {
+		return nil, errors.New("credential command is empty")
+	}
+
+	stderr := &bytes.Buffer{}
+
+	cmd := exec.Command(e.Command, e.Args...) //nolint:gosec // running the configured command is the point.
+	cmd.Env = append(os.Environ(), URLEnv+"="+u.String())
+	cmd.Stderr = stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential command %s failed: %w: %s", e.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	result := execOutput{}
+	if err := json.Unmarshal(output, &result); err != nil {
+		// the output isn't included, it likely contains a secret.
+		return nil, fmt.Errorf("failed to parse the output of credential command %s as JSON", e.Command)
+	}
+
+	return &Credentials{
+		Username: result.Username,
+		Password: result.Password,
+		Token:    result.Token,
+	}, nil
+}
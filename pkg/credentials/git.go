@@ -0,0 +1,55 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitHelper asks the git credential helpers configured for the URL with `git credential fill`.
+// Prompts are disabled, so URLs without stored credentials have none.
+type GitHelper struct{}
+
+// Lookup returns the username and password the helpers know for the URL.
+func (g *GitHelper) Lookup(u *url.URL) (*Credentials, error) {
+	input := fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/"))
+
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=", "GCM_INTERACTIVE=never")
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New("the git credential helper requires git to be installed")
+		}
+
+		// git fails if no helper has credentials and it isn't allowed to prompt.
+		return nil, nil
+	}
+
+	creds := &Credentials{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+
+		switch key {
+		case "username":
+			creds.Username = value
+		case "password":
+			creds.Password = value
+		}
+	}
+
+	if creds.Password == "" {
+		return nil, nil
+	}
+
+	return creds, nil
+}
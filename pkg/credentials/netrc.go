@@ -0,0 +1,107 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Netrc looks up credentials in a .netrc file by the host name of the URL. A `default` entry is used
+// for hosts without an entry.
+type Netrc struct {
+	// Path of the file. Default is $NETRC or ~/.netrc.
+	Path string
+}
+
+// Lookup returns the login and password of the host. A missing file has no credentials.
+func (n *Netrc) Lookup(u *url.URL) (*Credentials, error) {
+	path := n.Path
+	if path == "" {
+		path = os.Getenv("NETRC")
+	}
+
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil //nolint:nilerr // without a home folder there's no .netrc.
+		}
+
+		path = filepath.Join(home, ".netrc")
+	}
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read netrc file: %w", err)
+	}
+
+	return parseNetrc(string(content), u.Hostname()), nil
+}
+
+// parseNetrc returns the credentials of the first machine entry of host, or of the default entry.
+func parseNetrc(content, host string) *Credentials {
+	var (
+		current, fallback *Credentials
+		matched           bool
+	)
+
+	fields := strings.Fields(content)
+
+	for i := 0; i < len(fields); i++ {
+		var value string
+		if i+1 < len(fields) {
+			value = fields[i+1]
+		}
+
+		switch fields[i] {
+		case "machine":
+			if matched {
+				return current
+			}
+
+			current = &Credentials{}
+			matched = value == host
+			i++
+		case "default":
+			if matched {
+				return current
+			}
+
+			current = &Credentials{}
+			fallback = current
+		case "login":
+			if current != nil {
+				current.Username = value
+			}
+
+			i++
+		case "password":
+			if current != nil {
+				current.Password = value
+			}
+
+			i++
+		case "account":
+			i++
+		case "macdef":
+			// a macro runs until an empty line, which the fields don't keep, so nothing after it is read.
+			if matched {
+				return current
+			}
+
+			return fallback
+		}
+	}
+
+	if matched {
+		return current
+	}
+
+	return fallback
+}
@@ -0,0 +1,65 @@
+package credentials
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	redacted = "***"
+	// minSecretLength keeps very short secrets from mangling unrelated words of a message.
+	minSecretLength = 4
+)
+
+var (
+	// userPasswordPattern matches the password of the user info of a URL.
+	userPasswordPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^/?#@\s:]*):[^/?#@\s]*@`)
+	// userTokenPattern matches a token that is used as the user of an http URL.
+	userTokenPattern = regexp.MustCompile(`(https?://)[^/?#@\s:]+@`)
+)
+
+// Redact replaces the password and the token in s, and the secrets of URLs with user info, with `***`.
+func (c Credentials) Redact(s string) string {
+	for _, secret := range []string{c.Password, c.Token} {
+		if len(secret) >= minSecretLength {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+
+	return RedactURL(s)
+}
+
+// RedactError returns an error with a redacted message that still wraps err. It returns nil if err is nil.
+func (c Credentials) RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := c.Redact(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+
+	return &redactedError{msg: msg, err: err}
+}
+
+// RedactURL replaces the password of URLs with user info in s, and the user of http URLs, which is
+// commonly a token, with `***`.
+func RedactURL(s string) string {
+	s = userPasswordPattern.ReplaceAllString(s, "$1:"+redacted+"@")
+
+	return userTokenPattern.ReplaceAllString(s, "$1"+redacted+"@")
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,141 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
+)
+
+const decoderBufferSize = 4096
+
+// DecodeError is returned for content that doesn't decode as YAML or JSON objects, like a README or a
+// binary file. Sources that search for CRDs skip such files, but not CRDs that fail to be extracted.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeSchemaTypes streams every YAML or JSON document in content and extracts schema types
+// out of them. Lists, like `kind: List` or `CustomResourceDefinitionList`, are unwrapped and their
+// items are processed one by one. Documents that aren't CRDs are skipped and a note is written to log.
+// The source is used to identify the content in notes and errors.
+// Any Helm templating in the content is removed before decoding. Content that doesn't decode returns
+// a DecodeError.
+func DecodeSchemaTypes(content []byte, source string, log io.Writer) ([]*SchemaType, error) {
+	content, err := sanitize.Sanitize(content)
+	if err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf("failed to sanitize content: %w", err)}
+	}
+
+	return DecodeRenderedSchemaTypes(content, source, log)
+}
+
+// DecodeRenderedSchemaTypes works like DecodeSchemaTypes but expects content that was already
+// rendered, for example by Helm, so no template sanitization is applied.
+func DecodeRenderedSchemaTypes(content []byte, source string, log io.Writer) ([]*SchemaType, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), decoderBufferSize)
+
+	var result []*SchemaType
+
+	for index := 0; ; index++ {
+		var obj map[string]any
+		if err := decoder.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, &DecodeError{Err: fmt.Errorf("failed to decode document %d of %s: %w", index, source, err)}
+		}
+
+		// empty documents, for example a leading `---`, decode into nothing.
+		if len(obj) == 0 {
+			continue
+		}
+
+		schemaTypes, err := extractSchemaTypesFromObject(obj, fmt.Sprintf("%s[%d]", source, index), log)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, schemaTypes...)
+	}
+
+	return result, nil
+}
+
+func extractSchemaTypesFromObject(obj map[string]any, source string, log io.Writer) ([]*SchemaType, error) {
+	u := &unstructured.Unstructured{Object: obj}
+
+	if u.IsList() {
+		var result []*SchemaType
+
+		items, _ := obj["items"].([]any)
+		for i, item := range items {
+			itemObj, ok := item.(map[string]any)
+			if !ok {
+				_, _ = fmt.Fprintf(log, "skipping list item %s.items[%d]: not an object\n", source, i)
+
+				continue
+			}
+
+			schemaTypes, err := extractSchemaTypesFromObject(itemObj, fmt.Sprintf("%s.items[%d]", source, i), log)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, schemaTypes...)
+		}
+
+		return result, nil
+	}
+
+	if _, ok := obj["spec"].(map[string]any); !ok {
+		_, _ = fmt.Fprintf(log, "skipping none CRD document %s with kind %s\n", source, kindOf(u))
+
+		return nil, nil
+	}
+
+	if IsCompositeResourceDefinition(u) {
+		schemaTypes, err := ExtractCompositeSchemaTypes(u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract schema types from %s: %w", source, err)
+		}
+
+		return schemaTypes, nil
+	}
+
+	schemaType, err := ExtractSchemaType(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract schema type from %s: %w", source, err)
+	}
+
+	if schemaType == nil {
+		_, _ = fmt.Fprintf(log, "skipping none CRD document %s with kind %s\n", source, kindOf(u))
+
+		return nil, nil
+	}
+
+	return []*SchemaType{schemaType}, nil
+}
+
+func kindOf(u *unstructured.Unstructured) string {
+	if kind := strings.TrimSpace(u.GetKind()); kind != "" {
+		return kind
+	}
+
+	return "<unknown>"
+}
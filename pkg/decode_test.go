@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSchemaTypesMultipleDocuments(t *testing.T) {
+	crd, err := os.ReadFile(filepath.Join("testdata", "sample_crd.yaml"))
+	require.NoError(t, err)
+
+	validation, err := os.ReadFile(filepath.Join("testdata", "sample_crd_with_validation.yaml"))
+	require.NoError(t, err)
+
+	content := "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n---\n" + string(crd) + "\n---\n" + string(validation)
+
+	log := &bytes.Buffer{}
+	schemaTypes, err := DecodeSchemaTypes([]byte(content), "bundle.yaml", log)
+	require.NoError(t, err)
+
+	require.Len(t, schemaTypes, 2)
+	assert.Equal(t, "KrokCommand", schemaTypes[0].Kind)
+	assert.NotNil(t, schemaTypes[1].Validation)
+	assert.Equal(t, "skipping none CRD document bundle.yaml[0] with kind ConfigMap\n", log.String())
+}
+
+func TestDecodeSchemaTypesList(t *testing.T) {
+	content := `{
+  "apiVersion": "apiextensions.k8s.io/v1",
+  "kind": "CustomResourceDefinitionList",
+  "items": [
+    {
+      "kind": "CustomResourceDefinition",
+      "spec": {
+        "group": "example.com",
+        "names": {"kind": "First"},
+        "versions": [{"name": "v1", "schema": {"openAPIV3Schema": {"type": "object", "properties": {}}}}]
+      }
+    },
+    {
+      "kind": "Secret",
+      "metadata": {"name": "secret"}
+    },
+    {
+      "kind": "List",
+      "items": [
+        {
+          "kind": "CustomResourceDefinition",
+          "spec": {
+            "group": "example.com",
+            "names": {"kind": "Second"},
+            "versions": [{"name": "v1", "schema": {"openAPIV3Schema": {"type": "object", "properties": {}}}}]
+          }
+        }
+      ]
+    }
+  ]
+}`
+
+	log := &bytes.Buffer{}
+	schemaTypes, err := DecodeSchemaTypes([]byte(content), "list.json", log)
+	require.NoError(t, err)
+
+	require.Len(t, schemaTypes, 2)
+	assert.Equal(t, "First", schemaTypes[0].Kind)
+	assert.Equal(t, "Second", schemaTypes[1].Kind)
+	assert.Equal(t, "skipping none CRD document list.json[0].items[1] with kind Secret\n", log.String())
+}
+
+func TestDecodeSchemaTypesInvalidDocument(t *testing.T) {
+	_, err := DecodeSchemaTypes([]byte("kind: [invalid"), "broken.yaml", &bytes.Buffer{})
+	require.ErrorContains(t, err, "failed to decode document 0 of broken.yaml")
+
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+}
+
+func TestDecodeSchemaTypesInvalidCRD(t *testing.T) {
+	content := `{"kind": "CustomResourceDefinition", "spec": {"group": "example.com", "names": {"kind": "Broken"}, "versions": "v1"}}`
+
+	_, err := DecodeSchemaTypes([]byte(content), "broken.json", &bytes.Buffer{})
+	require.ErrorContains(t, err, "failed to extract schema type from broken.json[0]")
+
+	var decodeErr *DecodeError
+	assert.NotErrorAs(t, err, &decodeErr, "CRDs that fail to be extracted aren't skipped")
+}
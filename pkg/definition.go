@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+const (
+	typeString  = "string"
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+	typeObject  = "object"
+	typeAny     = "any"
+)
+
+// Definition is a language-neutral representation of a single version of a schema type.
+// It is used as the translation layer for definition outputs like CUE and Pkl.
+type Definition struct {
+	Group       string
+	Kind        string
+	Version     string
+	Description string
+	Fields      []*DefinitionField
+}
+
+// APIVersion returns the group/version combination of this definition.
+func (d *Definition) APIVersion() string {
+	return apiVersion(d.Group, d.Version)
+}
+
+// fieldDescription returns the description of the top-level field with the given name, or an empty
+// string if the schema doesn't define it.
+func (d *Definition) fieldDescription(name string) string {
+	for _, field := range d.Fields {
+		if field.Name == name {
+			return field.Description
+		}
+	}
+
+	return ""
+}
+
+// DefinitionField describes a single property with its constraints.
+type DefinitionField struct {
+	Name        string
+	Description string
+	// Type is one of string, integer, number, boolean, object, array or any.
+	Type     string
+	Required bool
+	Nullable bool
+	// Enum and Default contain raw JSON values.
+	Enum    []string
+	Default string
+
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum bool
+	ExclusiveMaximum bool
+	MinLength        *int64
+	MaxLength        *int64
+	MinItems         *int64
+	MaxItems         *int64
+	Pattern          string
+
+	// Fields are the properties of an object.
+	Fields []*DefinitionField
+	// Items is the element of an array.
+	Items *DefinitionField
+	// Values is the element type of map like objects defined through additionalProperties.
+	Values *DefinitionField
+	// Open is set when an object accepts any additional fields.
+	Open bool
+}
+
+// NewDefinitions translates every version of a schema type into a Definition.
+func NewDefinitions(crd *SchemaType) []*Definition {
+	var result []*Definition //nolint:prealloc // validation might be added
+
+	for _, v := range crd.Versions {
+		result = append(result, newDefinition(crd, v.Name, v.Schema))
+	}
+
+	if len(crd.Versions) == 0 && crd.Validation != nil {
+		result = append(result, newDefinition(crd, crd.Validation.Name, crd.Validation.Schema))
+	}
+
+	return result
+}
+
+func newDefinition(crd *SchemaType, version string, schema *v1beta1.JSONSchemaProps) *Definition {
+	def := &Definition{
+		Group:   crd.Group,
+		Kind:    crd.Kind,
+		Version: version,
+	}
+
+	if schema == nil {
+		return def
+	}
+
+	def.Description = schema.Description
+	def.Fields = newDefinitionFields(schema.Properties, schema.Required)
+
+	return def
+}
+
+func newDefinitionFields(properties map[string]v1beta1.JSONSchemaProps, required []string) []*DefinitionField {
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	fields := make([]*DefinitionField, 0, len(keys))
+	for _, k := range keys {
+		prop := properties[k]
+		field := newDefinitionField(&prop)
+		field.Name = k
+		field.Required = slices.Contains(required, k)
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func newDefinitionField(prop *v1beta1.JSONSchemaProps) *DefinitionField {
+	field := &DefinitionField{
+		Description:      strings.TrimSpace(prop.Description),
+		Type:             prop.Type,
+		Nullable:         prop.Nullable,
+		Minimum:          prop.Minimum,
+		Maximum:          prop.Maximum,
+		ExclusiveMinimum: prop.ExclusiveMinimum,
+		ExclusiveMaximum: prop.ExclusiveMaximum,
+		MinLength:        prop.MinLength,
+		MaxLength:        prop.MaxLength,
+		MinItems:         prop.MinItems,
+		MaxItems:         prop.MaxItems,
+		Pattern:          prop.Pattern,
+	}
+
+	if prop.Default != nil {
+		field.Default = string(prop.Default.Raw)
+	}
+
+	for _, e := range prop.Enum {
+		field.Enum = append(field.Enum, string(e.Raw))
+	}
+
+	if prop.XIntOrString || (field.Type == "" && len(prop.Properties) == 0) {
+		field.Type = typeAny
+	}
+
+	if field.Type == "" {
+		field.Type = typeObject
+	}
+
+	switch field.Type {
+	case typeObject:
+		field.Fields = newDefinitionFields(prop.Properties, prop.Required)
+
+		if prop.AdditionalProperties != nil {
+			if prop.AdditionalProperties.Schema != nil {
+				field.Values = newDefinitionField(prop.AdditionalProperties.Schema)
+			} else if prop.AdditionalProperties.Allows {
+				field.Open = true
+			}
+		}
+
+		if len(prop.Properties) == 0 && field.Values == nil {
+			field.Open = true
+		}
+
+		if prop.XPreserveUnknownFields != nil && *prop.XPreserveUnknownFields {
+			field.Open = true
+		}
+	case array:
+		field.Items = &DefinitionField{Type: typeAny}
+		if prop.Items != nil && prop.Items.Schema != nil {
+			field.Items = newDefinitionField(prop.Items.Schema)
+		}
+	}
+
+	return field
+}
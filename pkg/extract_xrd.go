@@ -0,0 +1,299 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+const (
+	crossplaneGroup = "apiextensions.crossplane.io"
+	xrdKind         = "CompositeResourceDefinition"
+	// crossplaneV2 moves the machinery fields of composite resources under spec.crossplane and drops claims.
+	crossplaneV2 = "v2"
+)
+
+type xrdNames struct {
+	Kind string `json:"kind"`
+}
+
+type xrdVersion struct {
+	Name   string `json:"name"`
+	Schema *struct {
+		OpenAPIV3Schema *v1beta1.JSONSchemaProps `json:"openAPIV3Schema"`
+	} `json:"schema"`
+}
+
+type xrdSpec struct {
+	Group      string       `json:"group"`
+	Names      xrdNames     `json:"names"`
+	ClaimNames *xrdNames    `json:"claimNames"`
+	Versions   []xrdVersion `json:"versions"`
+}
+
+// IsCompositeResourceDefinition returns true if the object is a Crossplane CompositeResourceDefinition.
+func IsCompositeResourceDefinition(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+
+	return gvk.Group == crossplaneGroup && gvk.Kind == xrdKind
+}
+
+// ExtractCompositeSchemaTypes creates a schema type for the composite resource of a Crossplane XRD and,
+// if claim names are defined, one for the claim. The fields Crossplane injects into composite resources
+// and claims, like compositionRef or writeConnectionSecretToRef, are added to the schema of every version
+// unless the XRD defines them itself.
+func ExtractCompositeSchemaTypes(obj *unstructured.Unstructured) ([]*SchemaType, error) {
+	spec, err := extractValue[map[string]any](obj.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+
+	xrd := &xrdSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, xrd); err != nil {
+		return nil, fmt.Errorf("failed to convert composite resource definition spec: %w", err)
+	}
+
+	if xrd.Names.Kind == "" || xrd.Group == "" {
+		return nil, errors.New("composite resource definition must define spec.group and spec.names.kind")
+	}
+
+	composite := &SchemaType{Group: xrd.Group, Kind: xrd.Names.Kind}
+
+	var claim *SchemaType
+	if xrd.ClaimNames != nil && xrd.ClaimNames.Kind != "" {
+		claim = &SchemaType{Group: xrd.Group, Kind: xrd.ClaimNames.Kind}
+	}
+
+	// v2 composite resources without claims keep the Crossplane fields under spec.crossplane.
+	nested := obj.GroupVersionKind().Version == crossplaneV2 && claim == nil
+
+	for _, v := range xrd.Versions {
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			return nil, fmt.Errorf("no schema found for version: %s", v.Name)
+		}
+
+		compositeSchema, err := withCrossplaneFields(v.Schema.OpenAPIV3Schema, compositeSpecFields(nested), nested)
+		if err != nil {
+			return nil, err
+		}
+
+		composite.Versions = append(composite.Versions, &CRDVersion{Name: v.Name, Schema: compositeSchema})
+
+		if claim == nil {
+			continue
+		}
+
+		claimSchema, err := withCrossplaneFields(v.Schema.OpenAPIV3Schema, claimSpecFields(), false)
+		if err != nil {
+			return nil, err
+		}
+
+		claim.Versions = append(claim.Versions, &CRDVersion{Name: v.Name, Schema: claimSchema})
+	}
+
+	if claim == nil {
+		return []*SchemaType{composite}, nil
+	}
+
+	return []*SchemaType{composite, claim}, nil
+}
+
+// withCrossplaneFields returns a copy of the schema with the injected spec fields, status fields, apiVersion,
+// kind and metadata. If nested is true the spec fields are added under spec.crossplane.
+func withCrossplaneFields(schema *v1beta1.JSONSchemaProps, specFields map[string]v1beta1.JSONSchemaProps, nested bool) (*v1beta1.JSONSchemaProps, error) {
+	result, err := copySchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Properties == nil {
+		result.Properties = map[string]v1beta1.JSONSchemaProps{}
+	}
+
+	if nested {
+		specFields = map[string]v1beta1.JSONSchemaProps{
+			"crossplane": {
+				Type:        typeObject,
+				Description: "Configures how Crossplane will reconcile this composite resource.",
+				Properties:  specFields,
+			},
+		}
+	}
+
+	result.Properties["spec"] = withDefaultProperties(result.Properties["spec"], specFields)
+	result.Properties["status"] = withDefaultProperties(result.Properties["status"], statusFields())
+
+	if _, ok := result.Properties["metadata"]; !ok {
+		result.Properties["metadata"] = v1beta1.JSONSchemaProps{Type: typeObject}
+	}
+
+	ensureKindAndAPIVersionIsSet(result.Properties)
+
+	return result, nil
+}
+
+// withDefaultProperties adds the properties that aren't defined by the schema yet.
+func withDefaultProperties(schema v1beta1.JSONSchemaProps, defaults map[string]v1beta1.JSONSchemaProps) v1beta1.JSONSchemaProps {
+	if schema.Type == "" {
+		schema.Type = typeObject
+	}
+
+	properties := maps.Clone(defaults)
+	maps.Copy(properties, schema.Properties)
+	schema.Properties = properties
+
+	return schema
+}
+
+func copySchema(schema *v1beta1.JSONSchemaProps) (*v1beta1.JSONSchemaProps, error) {
+	content, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy schema: %w", err)
+	}
+
+	result := &v1beta1.JSONSchemaProps{}
+	if err := json.Unmarshal(content, result); err != nil {
+		return nil, fmt.Errorf("failed to copy schema: %w", err)
+	}
+
+	return result, nil
+}
+
+// compositeSpecFields are the fields Crossplane injects into the spec of composite resources.
+// Composite resources that don't support claims don't have a claimRef.
+func compositeSpecFields(withoutClaim bool) map[string]v1beta1.JSONSchemaProps {
+	fields := compositionFields()
+	fields["claimRef"] = objectProps("A reference to the claim of this composite resource.", map[string]v1beta1.JSONSchemaProps{
+		"apiVersion": stringProps(""),
+		"kind":       stringProps(""),
+		"name":       stringProps(""),
+		"namespace":  stringProps(""),
+	}, "apiVersion", "kind", "name", "namespace")
+	fields["resourceRefs"] = v1beta1.JSONSchemaProps{
+		Type:        array,
+		Description: "References to the composed resources of this composite resource.",
+		Items: &v1beta1.JSONSchemaPropsOrArray{Schema: ptr(objectProps("", map[string]v1beta1.JSONSchemaProps{
+			"apiVersion": stringProps(""),
+			"kind":       stringProps(""),
+			"name":       stringProps(""),
+		}, "apiVersion", "kind"))},
+	}
+	fields["environmentConfigRefs"] = v1beta1.JSONSchemaProps{
+		Type:        array,
+		Description: "References to the environment configs used by the composition.",
+		Items: &v1beta1.JSONSchemaPropsOrArray{Schema: ptr(objectProps("", map[string]v1beta1.JSONSchemaProps{
+			"apiVersion": stringProps(""),
+			"kind":       stringProps(""),
+			"name":       stringProps(""),
+		}, "apiVersion", "kind"))},
+	}
+	fields["writeConnectionSecretToRef"] = objectProps("The secret the connection details of this composite resource are written to.",
+		map[string]v1beta1.JSONSchemaProps{
+			"name":      stringProps(""),
+			"namespace": stringProps(""),
+		}, "name", "namespace")
+
+	if withoutClaim {
+		delete(fields, "claimRef")
+	}
+
+	return fields
+}
+
+// claimSpecFields are the fields Crossplane injects into the spec of claims.
+func claimSpecFields() map[string]v1beta1.JSONSchemaProps {
+	fields := compositionFields()
+	fields["compositeDeletePolicy"] = enumProps("How the composite resource is deleted when the claim is deleted.", "Background", "Foreground")
+	fields["resourceRef"] = objectProps("A reference to the composite resource of this claim.", map[string]v1beta1.JSONSchemaProps{
+		"apiVersion": stringProps(""),
+		"kind":       stringProps(""),
+		"name":       stringProps(""),
+	}, "apiVersion", "kind", "name")
+	fields["writeConnectionSecretToRef"] = objectProps("The secret in the namespace of the claim the connection details are written to.",
+		map[string]v1beta1.JSONSchemaProps{
+			"name": stringProps(""),
+		}, "name")
+
+	return fields
+}
+
+// compositionFields select the composition, and its revision, that is used to compose resources.
+func compositionFields() map[string]v1beta1.JSONSchemaProps {
+	selector := func(description string) v1beta1.JSONSchemaProps {
+		return objectProps(description, map[string]v1beta1.JSONSchemaProps{
+			"matchLabels": {
+				Type:                 typeObject,
+				AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: ptr(stringProps(""))},
+			},
+		}, "matchLabels")
+	}
+
+	reference := func(description string) v1beta1.JSONSchemaProps {
+		return objectProps(description, map[string]v1beta1.JSONSchemaProps{"name": stringProps("")}, "name")
+	}
+
+	return map[string]v1beta1.JSONSchemaProps{
+		"compositionRef":              reference("The composition used to compose resources."),
+		"compositionSelector":         selector("Selects a composition by labels if compositionRef isn't set."),
+		"compositionRevisionRef":      reference("The composition revision used to compose resources."),
+		"compositionRevisionSelector": selector("Selects a composition revision by labels if compositionRevisionRef isn't set."),
+		"compositionUpdatePolicy":     enumProps("Whether new composition revisions are used automatically.", "Automatic", "Manual"),
+		"publishConnectionDetailsTo": objectProps("The store the connection details are published to.", map[string]v1beta1.JSONSchemaProps{
+			"name":      stringProps(""),
+			"configRef": reference(""),
+			"metadata": objectProps("", map[string]v1beta1.JSONSchemaProps{
+				"labels":      {Type: typeObject, AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: ptr(stringProps(""))}},
+				"annotations": {Type: typeObject, AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: ptr(stringProps(""))}},
+				"type":        stringProps(""),
+			}),
+		}, "name"),
+	}
+}
+
+// statusFields are the fields Crossplane injects into the status of composite resources and claims.
+func statusFields() map[string]v1beta1.JSONSchemaProps {
+	return map[string]v1beta1.JSONSchemaProps{
+		"conditions": {
+			Type:        array,
+			Description: "Conditions of the resource.",
+			Items: &v1beta1.JSONSchemaPropsOrArray{Schema: ptr(objectProps("", map[string]v1beta1.JSONSchemaProps{
+				"type":               stringProps(""),
+				"status":             stringProps(""),
+				"reason":             stringProps(""),
+				"message":            stringProps(""),
+				"lastTransitionTime": {Type: typeString, Format: "date-time"},
+				"observedGeneration": {Type: typeInteger, Format: "int64"},
+			}, "lastTransitionTime", "reason", "status", "type"))},
+		},
+		"connectionDetails": objectProps("", map[string]v1beta1.JSONSchemaProps{
+			"lastPublishedTime": {Type: typeString, Format: "date-time"},
+		}),
+	}
+}
+
+func objectProps(description string, properties map[string]v1beta1.JSONSchemaProps, required ...string) v1beta1.JSONSchemaProps {
+	return v1beta1.JSONSchemaProps{Type: typeObject, Description: description, Properties: properties, Required: required}
+}
+
+func stringProps(description string) v1beta1.JSONSchemaProps {
+	return v1beta1.JSONSchemaProps{Type: typeString, Description: description}
+}
+
+func enumProps(description string, values ...string) v1beta1.JSONSchemaProps {
+	enum := make([]v1beta1.JSON, 0, len(values))
+	for _, v := range values {
+		enum = append(enum, v1beta1.JSON{Raw: []byte(`"` + v + `"`)})
+	}
+
+	return v1beta1.JSONSchemaProps{Type: typeString, Description: description, Enum: enum}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
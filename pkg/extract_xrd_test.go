@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractCompositeSchemaTypes(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "crossplane_xrd.yaml"))
+	require.NoError(t, err)
+
+	log := &bytes.Buffer{}
+	schemaTypes, err := DecodeSchemaTypes(content, "xrd.yaml", log)
+	require.NoError(t, err)
+	require.Len(t, schemaTypes, 2)
+	assert.Empty(t, log.String())
+
+	composite, claim := schemaTypes[0], schemaTypes[1]
+	assert.Equal(t, "XPostgreSQLInstance", composite.Kind)
+	assert.Equal(t, "PostgreSQLInstance", claim.Kind)
+	assert.Equal(t, "database.example.org", claim.Group)
+	require.Len(t, composite.Versions, 1)
+	require.Len(t, claim.Versions, 1)
+
+	compositeSpec := composite.Versions[0].Schema.Properties["spec"]
+	assert.Contains(t, compositeSpec.Properties, "parameters")
+	assert.Contains(t, compositeSpec.Properties, "compositionRef")
+	assert.Contains(t, compositeSpec.Properties, "claimRef")
+	assert.Contains(t, compositeSpec.Properties, "resourceRefs")
+	assert.Contains(t, compositeSpec.Properties["writeConnectionSecretToRef"].Properties, "namespace")
+	assert.Equal(t, []string{"parameters"}, compositeSpec.Required)
+	// fields defined by the XRD win over the injected ones.
+	assert.Equal(t, "Only manual updates are allowed.", compositeSpec.Properties["compositionUpdatePolicy"].Description)
+
+	claimSpec := claim.Versions[0].Schema.Properties["spec"]
+	assert.Contains(t, claimSpec.Properties, "parameters")
+	assert.Contains(t, claimSpec.Properties, "compositeDeletePolicy")
+	assert.Contains(t, claimSpec.Properties, "resourceRef")
+	assert.NotContains(t, claimSpec.Properties, "claimRef")
+	assert.NotContains(t, claimSpec.Properties["writeConnectionSecretToRef"].Properties, "namespace")
+
+	for _, st := range schemaTypes {
+		status := st.Versions[0].Schema.Properties["status"]
+		assert.Contains(t, status.Properties, "address")
+		assert.Contains(t, status.Properties, "conditions")
+		assert.Contains(t, st.Versions[0].Schema.Properties, "apiVersion")
+		assert.Contains(t, st.Versions[0].Schema.Properties, "metadata")
+	}
+
+	// the injected fields must not leak into the schema of the XRD shared by both kinds.
+	assert.NotContains(t, claimSpec.Properties, "resourceRefs")
+
+	output := &bytes.Buffer{}
+	require.NoError(t, Generate(claim, &WriteNoOpCloser{w: output}, false, false, true))
+	assert.Contains(t, output.String(), "apiVersion: database.example.org/v1alpha1\nkind: PostgreSQLInstance\n")
+	assert.Contains(t, output.String(), `compositeDeletePolicy: "Background" # "Background", "Foreground"`)
+}
+
+func TestExtractCompositeSchemaTypesV2WithoutClaims(t *testing.T) {
+	content := `apiVersion: apiextensions.crossplane.io/v2
+kind: CompositeResourceDefinition
+spec:
+  group: example.org
+  scope: Namespaced
+  names:
+    kind: App
+  versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                image:
+                  type: string
+`
+
+	schemaTypes, err := DecodeSchemaTypes([]byte(content), "xrd.yaml", &bytes.Buffer{})
+	require.NoError(t, err)
+	require.Len(t, schemaTypes, 1)
+
+	spec := schemaTypes[0].Versions[0].Schema.Properties["spec"]
+	assert.Contains(t, spec.Properties, "image")
+	assert.NotContains(t, spec.Properties, "compositionRef")
+	assert.Contains(t, spec.Properties["crossplane"].Properties, "compositionRef")
+	assert.NotContains(t, spec.Properties["crossplane"].Properties, "claimRef")
+}
@@ -0,0 +1,231 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the timeout of a single request, including reading the body.
+	DefaultTimeout = 10 * time.Second
+	// DefaultRetries is the number of times a failed request is retried.
+	DefaultRetries = 3
+	// DefaultBackoff is the delay before the first retry. It doubles with every retry.
+	DefaultBackoff = time.Second
+	// maxBackoff caps the delay between retries, including the one requested by a Retry-After header.
+	maxBackoff = 30 * time.Second
+)
+
+// ClientOptions configure the HTTP client of URL, archive and OpenAPI sources. The TLS and proxy
+// settings are also used to clone git repositories over HTTPS.
+type ClientOptions struct {
+	// CABundle is a PEM encoded bundle that's trusted in addition to the system certificates.
+	CABundle []byte
+	// ClientCert and ClientKey are a PEM encoded certificate and key for mutual TLS.
+	ClientCert []byte
+	ClientKey  []byte
+	// Proxy is the URL of the proxy to use. HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used if empty.
+	Proxy string
+	// Timeout of a single attempt, including reading the body. Zero disables the timeout.
+	Timeout time.Duration
+	// Retries is the number of times a request is retried after a connection error or a
+	// 429 or 5xx response.
+	Retries int
+	// Backoff is the delay before the first retry. It doubles with every retry.
+	Backoff time.Duration
+}
+
+// NewClient returns an HTTP client configured with the options.
+func NewClient(opts ClientOptions) (*http.Client, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("default transport is not an http.Transport")
+	}
+
+	transport = transport.Clone()
+
+	if len(opts.CABundle) > 0 || len(opts.ClientCert) > 0 || len(opts.ClientKey) > 0 {
+		config, err := tlsConfig(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = config
+	}
+
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy url: %w", err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{
+		Transport: &retryTransport{
+			next:    transport,
+			timeout: opts.Timeout,
+			retries: opts.Retries,
+			backoff: opts.Backoff,
+		},
+	}, nil
+}
+
+func tlsConfig(opts ClientOptions) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(opts.CABundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(opts.CABundle) {
+			return nil, errors.New("failed to load any certificate from the CA bundle")
+		}
+
+		config.RootCAs = pool
+	}
+
+	if len(opts.ClientCert) > 0 || len(opts.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// retryTransport retries requests that failed with a transient connection error or a 429 or 5xx response.
+// Every attempt has its own timeout, which also covers reading the body.
+type retryTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+
+		if t.timeout > 0 {
+			ctx, cancel = context.WithTimeout(req.Context(), t.timeout)
+		} else {
+			ctx, cancel = context.WithCancel(req.Context())
+		}
+
+		r := req.Clone(ctx)
+
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+
+			r.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(r)
+
+		if attempt >= t.retries || !retryable(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			if err != nil {
+				cancel()
+
+				return nil, err
+			}
+
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+			return resp, nil
+		}
+
+		wait := t.delay(attempt, resp)
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		cancel()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// delay returns the exponential backoff of the attempt, or the delay of a Retry-After header in seconds.
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxBackoff)
+		}
+	}
+
+	return min(t.backoff<<attempt, maxBackoff)
+}
+
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return transientError(err)
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// transientError returns true for connection errors that may succeed on the next attempt. Permanent
+// failures, like a malformed URL or an invalid certificate, fail right away.
+func transientError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// cancelBody releases the context of an attempt once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
@@ -0,0 +1,240 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fetchWith(t *testing.T, opts ClientOptions, url string) ([]byte, error) {
+	t.Helper()
+
+	client, err := NewClient(opts)
+	require.NoError(t, err)
+
+	return NewFetcher(client, "", "", "").Fetch(url)
+}
+
+func serverCA(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func TestClientCABundle(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	// certificate errors aren't retried.
+	_, err := fetchWith(t, ClientOptions{Retries: 3, Backoff: time.Hour}, server.URL)
+	require.ErrorContains(t, err, "certificate")
+
+	content, err := fetchWith(t, ClientOptions{CABundle: serverCA(server)}, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, int32(1), requests.Load())
+
+	_, err = NewClient(ClientOptions{CABundle: []byte("not a certificate")})
+	require.ErrorContains(t, err, "failed to load any certificate from the CA bundle")
+}
+
+// newClientCertificate returns a CA and a client certificate and key signed by it.
+func newClientCertificate(t *testing.T) (*x509.Certificate, []byte, []byte) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	certDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return ca,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestClientCertificate(t *testing.T) {
+	ca, cert, key := newClientCertificate(t)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	_, err := fetchWith(t, ClientOptions{CABundle: serverCA(server)}, server.URL)
+	require.Error(t, err)
+
+	content, err := fetchWith(t, ClientOptions{CABundle: serverCA(server), ClientCert: cert, ClientKey: key}, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "client", string(content))
+
+	_, err = NewClient(ClientOptions{ClientCert: cert})
+	require.ErrorContains(t, err, "failed to load client certificate")
+}
+
+func TestClientRetries(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	_, err := fetchWith(t, ClientOptions{Retries: 1, Backoff: time.Millisecond}, server.URL)
+	require.ErrorContains(t, err, "status code 503")
+	assert.Equal(t, int32(2), requests.Load())
+
+	requests.Store(0)
+
+	content, err := fetchWith(t, ClientOptions{Retries: 3, Backoff: time.Millisecond}, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestClientTimeout(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+
+			return
+		}
+
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	_, err := fetchWith(t, ClientOptions{Timeout: 50 * time.Millisecond}, server.URL)
+	require.ErrorContains(t, err, "deadline exceeded")
+
+	requests.Store(0)
+
+	// every attempt has its own timeout.
+	content, err := fetchWith(t, ClientOptions{Timeout: 50 * time.Millisecond, Retries: 1, Backoff: time.Millisecond}, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+}
+
+func TestClientProxy(t *testing.T) {
+	var proxied atomic.Value
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.String())
+		_, _ = w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+
+	content, err := fetchWith(t, ClientOptions{Proxy: proxy.URL}, "http://crds.example.com/crd.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "proxied", string(content))
+	assert.Equal(t, "http://crds.example.com/crd.yaml", proxied.Load())
+}
+
+func TestRetryDelay(t *testing.T) {
+	transport := &retryTransport{backoff: time.Second}
+
+	assert.Equal(t, time.Second, transport.delay(0, nil))
+	assert.Equal(t, 4*time.Second, transport.delay(2, nil))
+	assert.Equal(t, maxBackoff, transport.delay(10, nil))
+	assert.Equal(t, 2*time.Second, transport.delay(0, &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}))
+}
+
+func TestTransientError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{name: "timeout", err: &url.Error{Op: "Get", Err: context.DeadlineExceeded}, transient: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, transient: true},
+		{name: "connection reset", err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, transient: true},
+		{name: "unexpected EOF", err: fmt.Errorf("failed to read: %w", io.ErrUnexpectedEOF), transient: true},
+		{name: "temporary DNS error", err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}, transient: true},
+		{name: "unknown host", err: &net.DNSError{Err: "no such host", IsNotFound: true}},
+		{name: "unsupported scheme", err: &url.Error{Op: "Get", Err: errors.New(`unsupported protocol scheme "htp"`)}},
+		{name: "certificate", err: &tls.CertificateVerificationError{Err: errors.New("unknown authority")}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.transient, transientError(tc.err))
+		})
+	}
+}
+
+func TestClientDoesNotRetryPermanentErrors(t *testing.T) {
+	start := time.Now()
+
+	_, err := fetchWith(t, ClientOptions{Retries: 3, Backoff: time.Second}, "htp://crds.example.com/crd.yaml")
+	require.ErrorContains(t, err, "unsupported protocol scheme")
+	assert.Less(t, time.Since(start), time.Second)
+}
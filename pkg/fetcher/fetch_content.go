@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
 )
 
 // Fetcher wraps an HTTP client.
@@ -13,10 +16,16 @@ type Fetcher struct {
 	username string
 	password string
 	token    string
+	cache    *cache.Cache
 }
 
-// NewFetcher constructs a new client wrapper with a given client.
+// NewFetcher constructs a new client wrapper with a given client. A nil client uses a client with the
+// default timeout. Use NewClient to configure TLS, a proxy and retries.
 func NewFetcher(client *http.Client, username, password, token string) *Fetcher {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
 	return &Fetcher{
 		client:   client,
 		username: username,
@@ -25,8 +34,97 @@ func NewFetcher(client *http.Client, username, password, token string) *Fetcher
 	}
 }
 
+// WithCache keeps fetched content in the cache. Cached content is revalidated with its ETag or
+// Last-Modified header, and returned without a request in offline mode. A nil cache disables caching.
+func (f *Fetcher) WithCache(c *cache.Cache) *Fetcher {
+	f.cache = c
+
+	return f
+}
+
 // Fetch constructs a request and does a client.Do with it.
 func (f *Fetcher) Fetch(url string) ([]byte, error) {
+	body, err := f.Open(url)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	return content, nil
+}
+
+// Open is like Fetch, but returns the body as a stream so large content, like archives, isn't read
+// into memory. With a cache, the body is streamed into the cache and read back from there. The
+// caller closes the body.
+func (f *Fetcher) Open(url string) (io.ReadCloser, error) {
+	var (
+		entry  *cache.Entry
+		cached *os.File
+	)
+
+	if f.cache != nil {
+		var err error
+
+		entry, cached, err = f.cache.OpenHTTP(url)
+		if err != nil {
+			return nil, err
+		}
+
+		if f.cache.Offline() {
+			if entry == nil {
+				return nil, fmt.Errorf("url '%s' is not cached and can't be fetched in offline mode", url)
+			}
+
+			return cached, nil
+		}
+	}
+
+	resp, err := f.request(url, entry)
+	if err != nil {
+		closeFile(cached)
+
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		_ = resp.Body.Close()
+
+		return cached, nil
+	}
+
+	closeFile(cached)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		_ = resp.Body.Close()
+
+		return nil, fmt.Errorf("failed to fetch url content with status code %d", resp.StatusCode)
+	}
+
+	if f.cache == nil {
+		return resp.Body, nil
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	return f.cache.StoreHTTPReader(&cache.Entry{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, resp.Body)
+}
+
+// request sends the request of the URL. A cached entry is revalidated with its validators.
+func (f *Fetcher) request(url string, entry *cache.Entry) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate request for url '%s': %w", url, err)
@@ -40,26 +138,26 @@ func (f *Fetcher) Fetch(url string) ([]byte, error) {
 		req.Header.Add("Authorization", "Bearer "+f.token)
 	}
 
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch data: %w", err)
-	}
-
-	defer func() {
-		closeErr := resp.Body.Close()
-		if closeErr != nil {
-			err = fmt.Errorf("failed to close with %w after %w", closeErr, err)
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
 		}
-	}()
 
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("failed to fetch url content with status code %d", resp.StatusCode)
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
 	}
 
-	content, err := io.ReadAll(resp.Body)
+	resp, err := f.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read body: %w", err)
+		return nil, fmt.Errorf("failed to fetch data: %w", err)
 	}
 
-	return content, nil
+	return resp, nil
+}
+
+func closeFile(file *os.File) {
+	if file != nil {
+		_ = file.Close()
+	}
 }
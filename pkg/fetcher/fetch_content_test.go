@@ -0,0 +1,132 @@
+package fetcher
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+)
+
+func TestFetchWithCache(t *testing.T) {
+	var requests, notModified atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	fetch := func(offline, refresh bool) ([]byte, error) {
+		c, err := cache.New(dir, offline, refresh)
+		require.NoError(t, err)
+
+		return NewFetcher(server.Client(), "", "", "").WithCache(c).Fetch(server.URL)
+	}
+
+	content, err := fetch(false, false)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, int32(0), notModified.Load())
+
+	content, err = fetch(false, false)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, int32(1), notModified.Load())
+
+	content, err = fetch(true, false)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, int32(2), requests.Load())
+
+	content, err = fetch(false, true)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, int32(1), notModified.Load())
+}
+
+func TestFetchOfflineNotCached(t *testing.T) {
+	c, err := cache.New(t.TempDir(), true, false)
+	require.NoError(t, err)
+
+	_, err = NewFetcher(http.DefaultClient, "", "", "").WithCache(c).Fetch("https://example.com/crd.yaml")
+	require.ErrorContains(t, err, "is not cached and can't be fetched in offline mode")
+}
+
+func TestFetchLastModified(t *testing.T) {
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+	var revalidated atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			revalidated.Store(true)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	c, err := cache.New(t.TempDir(), false, false)
+	require.NoError(t, err)
+
+	f := NewFetcher(server.Client(), "", "", "").WithCache(c)
+
+	for range 2 {
+		content, err := f.Fetch(server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+	}
+
+	assert.True(t, revalidated.Load())
+}
+
+func TestOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	c, err := cache.New(t.TempDir(), false, false)
+	require.NoError(t, err)
+
+	for _, f := range []*Fetcher{
+		NewFetcher(server.Client(), "", "", ""),
+		NewFetcher(server.Client(), "", "", "").WithCache(c),
+	} {
+		body, err := f.Open(server.URL)
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.NoError(t, body.Close())
+		assert.Equal(t, "content", string(content))
+	}
+
+	entry, data, err := c.HTTP(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, entry.ETag)
+	assert.Equal(t, "content", string(data))
+}
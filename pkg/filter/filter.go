@@ -0,0 +1,190 @@
+// Package filter contains the file selection rules that are shared by every source
+// which discovers CRDs in a tree of files, like folders, git repositories and archives.
+package filter
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// extensions that are considered to contain CRDs. Files without an extension are
+// considered as well, but only kept if their content parses as a CRD.
+var extensions = []string{".yaml", ".yml", ".json"}
+
+// Filter selects files based on include and exclude glob patterns. Patterns are matched
+// against the slash separated path relative to the root of the source. Besides the usual
+// `*`, `?` and `[...]` wildcards, `**` matches any number of folders.
+type Filter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	// prefixes are the folders before the first wildcard of the include patterns.
+	prefixes []string
+}
+
+// New compiles the include and exclude patterns into a Filter.
+func New(include, exclude []string) (*Filter, error) {
+	f := &Filter{}
+
+	for _, p := range include {
+		r, err := compile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		f.include = append(f.include, r)
+		f.prefixes = append(f.prefixes, prefix(p))
+	}
+
+	for _, p := range exclude {
+		r, err := compile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		f.exclude = append(f.exclude, r)
+	}
+
+	return f, nil
+}
+
+// Match returns true if the path is included and not excluded. If there are no include
+// patterns every path is included. A nil Filter matches everything.
+func (f *Filter) Match(p string) bool {
+	if f == nil {
+		return true
+	}
+
+	p = strings.TrimPrefix(filepath.ToSlash(p), "./")
+
+	for _, r := range f.exclude {
+		if r.MatchString(p) {
+			return false
+		}
+	}
+
+	if len(f.include) == 0 {
+		return true
+	}
+
+	for _, r := range f.include {
+		if r.MatchString(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MayContain returns true if files below the folder can be included. Sources use it to skip
+// folders without reading them. A nil Filter, or one without include patterns, contains everything.
+func (f *Filter) MayContain(dir string) bool {
+	if f == nil || len(f.prefixes) == 0 {
+		return true
+	}
+
+	dir = strings.Trim(strings.TrimPrefix(filepath.ToSlash(dir), "./"), "/") + "/"
+
+	for _, p := range f.prefixes {
+		if strings.HasPrefix(p, dir) || strings.HasPrefix(dir, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Candidate returns true if a file might contain CRDs based on its name. These are
+// YAML and JSON files and files without an extension.
+func Candidate(p string) bool {
+	ext := strings.ToLower(path.Ext(filepath.ToSlash(p)))
+	if ext == "" {
+		return true
+	}
+
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasExtension returns true if the file has one of the known CRD extensions. Files without one
+// need their content checked before they are reported as problems.
+func HasExtension(p string) bool {
+	return path.Ext(filepath.ToSlash(p)) != "" && Candidate(p)
+}
+
+// prefix returns the folders of the pattern before the first wildcard, ending with a slash, or
+// an empty string if the first folder already contains one.
+func prefix(pattern string) string {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+
+	if i := strings.IndexAny(pattern, "*?["); i >= 0 {
+		pattern = pattern[:i]
+	}
+
+	return pattern[:strings.LastIndex(pattern, "/")+1]
+}
+
+// compile translates a glob pattern into an anchored regular expression.
+func compile(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				// `**/` matches zero or more folders, a trailing `**` matches everything.
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+
+				continue
+			}
+
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid pattern %q: missing closing ]", pattern)
+			}
+
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	r, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	return r, nil
+}
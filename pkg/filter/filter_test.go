@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		path    string
+		want    bool
+	}{
+		{name: "no patterns", path: "crds/a.yaml", want: true},
+		{name: "double star include", include: []string{"config/crd/**"}, path: "config/crd/bases/a.yaml", want: true},
+		{name: "double star include miss", include: []string{"config/crd/**"}, path: "config/rbac/role.yaml", want: false},
+		{name: "double star in the middle", include: []string{"**/crds/*.yaml"}, path: "charts/app/crds/a.yaml", want: true},
+		{name: "double star matches no folder", include: []string{"**/crds/*.yaml"}, path: "crds/a.yaml", want: true},
+		{name: "single star stays in folder", include: []string{"crds/*.yaml"}, path: "crds/nested/a.yaml", want: false},
+		{name: "exclude wins", include: []string{"**"}, exclude: []string{"**/testdata/**"}, path: "pkg/testdata/a.yaml", want: false},
+		{name: "question mark", include: []string{"v?/a.yaml"}, path: "v1/a.yaml", want: true},
+		{name: "character class", include: []string{"[ab].yaml"}, path: "c.yaml", want: false},
+		{name: "negated character class", include: []string{"[!ab].yaml"}, path: "c.yaml", want: true},
+		{name: "leading dot slash", include: []string{"./crds/*"}, path: "./crds/a.yml", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.include, tt.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, f.Match(tt.path))
+		})
+	}
+}
+
+func TestFilterMayContain(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		dir     string
+		want    bool
+	}{
+		{name: "no patterns", dir: "test", want: true},
+		{name: "parent of prefix", include: []string{"config/crd/**"}, dir: "config", want: true},
+		{name: "prefix", include: []string{"config/crd/**"}, dir: "config/crd", want: true},
+		{name: "below prefix", include: []string{"config/crd/**"}, dir: "config/crd/bases", want: true},
+		{name: "sibling of prefix", include: []string{"config/crd/**"}, dir: "config/rbac", want: false},
+		{name: "similar name", include: []string{"config/crd/**"}, dir: "config/crds", want: false},
+		{name: "leading double star", include: []string{"**/crds/*.yaml"}, dir: "charts/app", want: true},
+		{name: "wildcard folder", include: []string{"charts/*/crds/*.yaml"}, dir: "charts/app", want: true},
+		{name: "file pattern", include: []string{"crds/a.yaml"}, dir: "docs", want: false},
+		{name: "any pattern", include: []string{"docs/*", "crds/*"}, dir: "crds", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.include, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, f.MayContain(tt.dir))
+		})
+	}
+}
+
+func TestNilFilterMatchesEverything(t *testing.T) {
+	var f *Filter
+	assert.True(t, f.Match("anything/at/all.json"))
+}
+
+func TestInvalidPattern(t *testing.T) {
+	_, err := New([]string{"[abc"}, nil)
+	require.ErrorContains(t, err, "missing closing ]")
+}
+
+func TestCandidate(t *testing.T) {
+	assert.True(t, Candidate("a.yaml"))
+	assert.True(t, Candidate("a.YML"))
+	assert.True(t, Candidate("a.json"))
+	assert.True(t, Candidate("crds/README"))
+	assert.False(t, Candidate("main.go"))
+	assert.False(t, HasExtension("crds/README"))
+	assert.True(t, HasExtension("a.yml"))
+}
@@ -132,7 +132,7 @@ func (p *Parser) ParseProperties(version string, file io.Writer, properties map[
 		switch {
 		case len(properties[k].Properties) == 0 && properties[k].AdditionalProperties == nil:
 			if k == "apiVersion" {
-				w.write(file, fmt.Sprintf(" %s/%s\n", p.group, version))
+				w.write(file, " "+apiVersion(p.group, version)+"\n")
 
 				continue
 			}
@@ -144,7 +144,7 @@ func (p *Parser) ParseProperties(version string, file io.Writer, properties map[
 			}
 			// If we are dealing with an array, and we have properties to parse
 			// we need to reparse all of them again.
-			if properties[k].Type == array && properties[k].Items.Schema != nil && len(properties[k].Items.Schema.Properties) > 0 {
+			if properties[k].Type == array && properties[k].Items != nil && properties[k].Items.Schema != nil && len(properties[k].Items.Schema.Properties) > 0 {
 				w.write(file, fmt.Sprintf("\n%s- ", strings.Repeat(" ", p.indent)))
 				p.indent += 2
 				p.inArray = true
@@ -274,7 +274,7 @@ func outputValueType(v v1beta1.JSONSchemaProps, skipRandom bool) string {
 	case "object":
 		return "{}"
 	case array: // deal with arrays of other types that weren't objects
-		if v.Items.Schema != nil {
+		if v.Items != nil && v.Items.Schema != nil {
 			t := v.Items.Schema.Type
 
 			var (
@@ -298,3 +298,12 @@ func outputValueType(v v1beta1.JSONSchemaProps, skipRandom bool) string {
 
 	return v.Type
 }
+
+// apiVersion returns the apiVersion of a kind. Kinds of the core group only have a version.
+func apiVersion(group, version string) string {
+	if group == "" {
+		return version
+	}
+
+	return group + "/" + version
+}
@@ -0,0 +1,344 @@
+// Package gitsource clones git repositories and walks the files of a commit to discover CRDs.
+package gitsource
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+)
+
+// mirrorRefSpecs keep the branches and tags of the mirror identical to the remote.
+var mirrorRefSpecs = []config.RefSpec{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"}
+
+// Options define the repository and the ref to clone.
+type Options struct {
+	URL string
+	// Ref is a branch, tag or commit SHA. The default branch is used if empty.
+	Ref      string
+	Auth     transport.AuthMethod
+	CABundle []byte
+	// ClientCert and ClientKey are used for mutual TLS over HTTPS.
+	ClientCert []byte
+	ClientKey  []byte
+	// Proxy is the URL of the proxy to use. HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used if empty.
+	Proxy string
+	// Dir keeps a bare mirror of the repository on disk. An existing mirror is updated with a fetch
+	// instead of cloning the repository again.
+	Dir string
+	// Offline uses the mirror in Dir without fetching.
+	Offline bool
+	// Path opens an existing local repository, or a folder inside of it, instead of cloning URL.
+	// A file:// URL is opened the same way.
+	Path string
+}
+
+// LocalPath returns the location of the local repository, or an empty string if it has to be cloned.
+func (o Options) LocalPath() string {
+	if o.Path != "" {
+		return o.Path
+	}
+
+	if p, ok := strings.CutPrefix(o.URL, "file://"); ok {
+		return p
+	}
+
+	return ""
+}
+
+// Clone clones the repository into memory and returns it with the commit of the ref. The default branch,
+// branches and tags are cloned with a depth of one and a single branch. Other refs, like commit SHAs, need
+// the full history because servers don't have to serve commits that aren't the tip of a ref. If Dir is set,
+// the full repository is mirrored there instead, so later calls only fetch what changed. Local repositories
+// aren't cloned at all.
+func Clone(opts Options) (*git.Repository, *object.Commit, error) {
+	if p := opts.LocalPath(); p != "" {
+		return openLocal(p, opts.Ref)
+	}
+
+	if opts.Dir != "" {
+		return mirror(opts)
+	}
+
+	cloneOpts := &git.CloneOptions{
+		URL:          opts.URL,
+		Auth:         opts.Auth,
+		CABundle:     opts.CABundle,
+		ClientCert:   opts.ClientCert,
+		ClientKey:    opts.ClientKey,
+		ProxyOptions: transport.ProxyOptions{URL: opts.Proxy},
+		Depth:        1,
+		SingleBranch: true,
+		Tags:         git.NoTags,
+	}
+
+	revision := plumbing.Revision(plumbing.HEAD)
+
+	if opts.Ref != "" {
+		name, err := remoteReference(opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if name != "" {
+			cloneOpts.ReferenceName = name
+			revision = plumbing.Revision(name)
+		} else {
+			cloneOpts.Depth = 0
+			cloneOpts.SingleBranch = false
+			cloneOpts.Tags = git.AllTags
+			revision = plumbing.Revision(opts.Ref)
+		}
+	}
+
+	r, err := git.Clone(memory.NewStorage(), nil, cloneOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error cloning git repository: %w", err)
+	}
+
+	commit, err := resolve(r, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, commit, nil
+}
+
+// Open returns the repository with every branch and tag, so any number of refs can be read from a single
+// clone. The ref of the options is ignored. If Dir is set, the mirror there is used.
+func Open(opts Options) (*git.Repository, error) {
+	if p := opts.LocalPath(); p != "" {
+		return plainOpen(p)
+	}
+
+	if opts.Dir != "" {
+		return openMirror(opts)
+	}
+
+	r, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+		URL:          opts.URL,
+		Auth:         opts.Auth,
+		CABundle:     opts.CABundle,
+		ClientCert:   opts.ClientCert,
+		ClientKey:    opts.ClientKey,
+		ProxyOptions: transport.ProxyOptions{URL: opts.Proxy},
+		Tags:         git.AllTags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error cloning git repository: %w", err)
+	}
+
+	return r, nil
+}
+
+// mirror opens or creates the bare mirror in Dir, fetches every branch and tag and returns the commit of the ref.
+func mirror(opts Options) (*git.Repository, *object.Commit, error) {
+	r, err := openMirror(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	revision := plumbing.Revision(plumbing.HEAD)
+	if opts.Ref != "" {
+		revision = plumbing.Revision(opts.Ref)
+	}
+
+	commit, err := resolve(r, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, commit, nil
+}
+
+func openMirror(opts Options) (*git.Repository, error) {
+	r, err := git.PlainOpen(opts.Dir)
+
+	switch {
+	case errors.Is(err, git.ErrRepositoryNotExists) && opts.Offline:
+		return nil, fmt.Errorf("git repository %s is not cached and can't be cloned in offline mode", opts.URL)
+	case errors.Is(err, git.ErrRepositoryNotExists):
+		r, err = initMirror(opts)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to open cached git repository %s: %w", opts.Dir, err)
+	}
+
+	if !opts.Offline {
+		if err := fetch(r, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func initMirror(opts Options) (*git.Repository, error) {
+	r, err := git.PlainInit(opts.Dir, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cached git repository %s: %w", opts.Dir, err)
+	}
+
+	if _, err := r.CreateRemote(&config.RemoteConfig{
+		Name:  git.DefaultRemoteName,
+		URLs:  []string{opts.URL},
+		Fetch: mirrorRefSpecs,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to configure cached git repository %s: %w", opts.Dir, err)
+	}
+
+	return r, nil
+}
+
+// fetch updates every branch and tag of the mirror and points HEAD to the default branch of the remote.
+func fetch(r *git.Repository, opts Options) error {
+	remote, err := r.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return fmt.Errorf("failed to get remote of cached git repository %s: %w", opts.Dir, err)
+	}
+
+	refs, err := remote.List(listOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to list references of git repository: %w", err)
+	}
+
+	err = remote.Fetch(&git.FetchOptions{
+		RefSpecs:     mirrorRefSpecs,
+		Auth:         opts.Auth,
+		CABundle:     opts.CABundle,
+		ClientCert:   opts.ClientCert,
+		ClientKey:    opts.ClientKey,
+		ProxyOptions: transport.ProxyOptions{URL: opts.Proxy},
+		Tags:         git.AllTags,
+		Force:        true,
+		Prune:        true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("error fetching git repository: %w", err)
+	}
+
+	for _, ref := range refs {
+		if ref.Name() != plumbing.HEAD {
+			continue
+		}
+
+		if err := r.Storer.SetReference(ref); err != nil {
+			return fmt.Errorf("failed to update HEAD of cached git repository %s: %w", opts.Dir, err)
+		}
+	}
+
+	return nil
+}
+
+func listOptions(opts Options) *git.ListOptions {
+	return &git.ListOptions{
+		Auth:         opts.Auth,
+		CABundle:     opts.CABundle,
+		ClientCert:   opts.ClientCert,
+		ClientKey:    opts.ClientKey,
+		ProxyOptions: transport.ProxyOptions{URL: opts.Proxy},
+	}
+}
+
+func resolve(r *git.Repository, revision plumbing.Revision) (*object.Commit, error) {
+	hash, err := r.ResolveRevision(revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+
+	commit, err := r.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting commit object: %w", err)
+	}
+
+	return commit, nil
+}
+
+// remoteReference returns the branch or tag the ref refers to, or an empty name if it's neither.
+func remoteReference(opts Options) (plumbing.ReferenceName, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{opts.URL},
+	})
+
+	refs, err := remote.List(listOptions(opts))
+	if err != nil {
+		return "", fmt.Errorf("failed to list references of git repository: %w", err)
+	}
+
+	candidates := []plumbing.ReferenceName{
+		plumbing.ReferenceName(opts.Ref),
+		plumbing.NewBranchReferenceName(opts.Ref),
+		plumbing.NewTagReferenceName(opts.Ref),
+	}
+
+	for _, candidate := range candidates {
+		for _, ref := range refs {
+			if ref.Name() == candidate && (candidate.IsBranch() || candidate.IsTag()) {
+				return candidate, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// Walk calls fn for every file of the commit that matches paths. Folders that can't contain a match
+// aren't read. A nil paths filter matches every file. Submodules are skipped.
+func Walk(commit *object.Commit, paths *filter.Filter, fn func(name string, f *object.File) error) error {
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("error getting commit tree: %w", err)
+	}
+
+	return walkTree(tree, "", paths, fn)
+}
+
+func walkTree(tree *object.Tree, dir string, paths *filter.Filter, fn func(name string, f *object.File) error) error {
+	for _, entry := range tree.Entries {
+		name := path.Join(dir, entry.Name)
+
+		switch {
+		case entry.Mode == filemode.Dir:
+			if !paths.MayContain(name) {
+				continue
+			}
+
+			subtree, err := tree.Tree(entry.Name)
+			if err != nil {
+				return fmt.Errorf("error getting tree %s: %w", name, err)
+			}
+
+			if err := walkTree(subtree, name, paths, fn); err != nil {
+				return err
+			}
+		case entry.Mode.IsFile():
+			if !paths.Match(name) {
+				continue
+			}
+
+			f, err := tree.TreeEntryFile(&entry)
+			if err != nil {
+				return fmt.Errorf("error getting file %s: %w", name, err)
+			}
+
+			if err := fn(name, f); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
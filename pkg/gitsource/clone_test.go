@@ -0,0 +1,260 @@
+package gitsource
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+)
+
+type testRepository struct {
+	url     string
+	tagged  plumbing.Hash
+	main    plumbing.Hash
+	feature plumbing.Hash
+	// push commits the files to the feature branch and pushes it.
+	push func(files ...string) plumbing.Hash
+}
+
+// newTestRepository creates a bare repository with a tagged commit, a second commit on master and a
+// feature branch.
+func newTestRepository(t *testing.T) *testRepository {
+	t.Helper()
+
+	bare := filepath.Join(t.TempDir(), "bare.git")
+	_, err := git.PlainInit(bare, true)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	w, err := r.Worktree()
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(0, 0)}
+
+	commit := func(files ...string) plumbing.Hash {
+		for _, f := range files {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("name: "+f+"\n"), 0o600))
+			_, err := w.Add(f)
+			require.NoError(t, err)
+		}
+
+		hash, err := w.Commit("add "+files[0], &git.CommitOptions{Author: signature})
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	repo := &testRepository{url: bare}
+	repo.tagged = commit("config/crd/bases/a.yaml", "docs/crd.yaml", "README.md")
+
+	_, err = r.CreateTag("v1.0.0", repo.tagged, &git.CreateTagOptions{Tagger: signature, Message: "v1.0.0"})
+	require.NoError(t, err)
+
+	repo.main = commit("config/crd/bases/b.yaml")
+
+	require.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}))
+	repo.feature = commit("config/crd/bases/c.yaml")
+
+	_, err = r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{bare}})
+	require.NoError(t, err)
+
+	push := func() {
+		require.NoError(t, r.Push(&git.PushOptions{RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}}))
+	}
+	push()
+
+	repo.push = func(files ...string) plumbing.Hash {
+		hash := commit(files...)
+		push()
+
+		return hash
+	}
+
+	return repo
+}
+
+func files(t *testing.T, commit *object.Commit, paths *filter.Filter) []string {
+	t.Helper()
+
+	var result []string
+
+	require.NoError(t, Walk(commit, paths, func(name string, _ *object.File) error {
+		result = append(result, name)
+
+		return nil
+	}))
+
+	return result
+}
+
+func TestClone(t *testing.T) {
+	repo := newTestRepository(t)
+
+	testCases := []struct {
+		name    string
+		ref     string
+		commit  plumbing.Hash
+		shallow bool
+	}{
+		{name: "default branch", commit: repo.main, shallow: true},
+		{name: "branch", ref: "feature", commit: repo.feature, shallow: true},
+		{name: "full branch name", ref: "refs/heads/feature", commit: repo.feature, shallow: true},
+		{name: "annotated tag", ref: "v1.0.0", commit: repo.tagged, shallow: true},
+		{name: "commit", ref: repo.tagged.String(), commit: repo.tagged},
+		{name: "abbreviated commit", ref: repo.tagged.String()[:8], commit: repo.tagged},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, commit, err := Clone(Options{URL: repo.url, Ref: tc.ref})
+			require.NoError(t, err)
+			assert.Equal(t, tc.commit, commit.Hash)
+
+			shallow, err := r.Storer.Shallow()
+			require.NoError(t, err)
+			assert.Equal(t, tc.shallow, len(shallow) > 0)
+		})
+	}
+}
+
+func TestCloneUnknownRef(t *testing.T) {
+	repo := newTestRepository(t)
+
+	_, _, err := Clone(Options{URL: repo.url, Ref: "missing"})
+	require.ErrorContains(t, err, "failed to resolve revision missing")
+}
+
+func TestWalk(t *testing.T) {
+	repo := newTestRepository(t)
+
+	_, commit, err := Clone(Options{URL: repo.url, Ref: "feature"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"README.md",
+		"config/crd/bases/a.yaml",
+		"config/crd/bases/b.yaml",
+		"config/crd/bases/c.yaml",
+		"docs/crd.yaml",
+	}, files(t, commit, nil))
+
+	paths, err := filter.New([]string{"config/crd/**"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"config/crd/bases/a.yaml",
+		"config/crd/bases/b.yaml",
+		"config/crd/bases/c.yaml",
+	}, files(t, commit, paths))
+
+	paths, err = filter.New([]string{"**/crd.yaml"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/crd.yaml"}, files(t, commit, paths))
+}
+
+func TestCloneMirror(t *testing.T) {
+	repo := newTestRepository(t)
+	dir := filepath.Join(t.TempDir(), "mirror")
+
+	_, _, err := Clone(Options{URL: repo.url, Dir: dir, Offline: true})
+	require.ErrorContains(t, err, "is not cached and can't be cloned in offline mode")
+
+	_, commit, err := Clone(Options{URL: repo.url, Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, repo.main, commit.Hash)
+
+	_, commit, err = Clone(Options{URL: repo.url, Ref: "v1.0.0", Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, repo.tagged, commit.Hash)
+
+	update := repo.push("config/crd/bases/d.yaml")
+
+	// offline the mirror isn't updated.
+	_, commit, err = Clone(Options{URL: repo.url, Ref: "feature", Dir: dir, Offline: true})
+	require.NoError(t, err)
+	assert.Equal(t, repo.feature, commit.Hash)
+
+	_, commit, err = Clone(Options{URL: repo.url, Ref: "feature", Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, update, commit.Hash)
+
+	_, commit, err = Clone(Options{URL: repo.url, Ref: repo.feature.String(), Dir: dir, Offline: true})
+	require.NoError(t, err)
+	assert.Equal(t, repo.feature, commit.Hash)
+}
+
+func TestOpenTags(t *testing.T) {
+	repo := newTestRepository(t)
+
+	bare, err := git.PlainOpen(repo.url)
+	require.NoError(t, err)
+
+	for name, hash := range map[string]plumbing.Hash{"v1.10.0": repo.feature, "v1.2.0": repo.main, "nightly": repo.main} {
+		_, err := bare.CreateTag(name, hash, nil)
+		require.NoError(t, err)
+	}
+
+	for _, dir := range []string{"", filepath.Join(t.TempDir(), "mirror")} {
+		r, err := Open(Options{URL: repo.url, Ref: "ignored", Dir: dir})
+		require.NoError(t, err)
+
+		tags, err := Tags(r, "v1.*")
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+
+		assert.Equal(t, []string{"v1.0.0", "v1.2.0", "v1.10.0"}, names)
+		assert.Equal(t, repo.tagged, tags[0].Commit.Hash)
+		assert.Equal(t, repo.feature, tags[2].Commit.Hash)
+
+		tags, err = Tags(r, "")
+		require.NoError(t, err)
+		assert.Len(t, tags, 4)
+	}
+
+	r, err := Open(Options{URL: repo.url})
+	require.NoError(t, err)
+
+	_, err = Tags(r, "v1.[")
+	require.ErrorContains(t, err, "invalid tag pattern")
+}
+
+func TestCompareTags(t *testing.T) {
+	at := func(name string, hour int) Tag {
+		return Tag{Name: name, Commit: &object.Commit{Committer: object.Signature{When: time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC)}}}
+	}
+
+	tags := []Tag{at("nightly", 1), at("v1.10.0", 2), at("latest", 3), at("v1.2.0", 4), at("edge", 1)}
+	want := []string{"v1.2.0", "v1.10.0", "edge", "nightly", "latest"}
+
+	// the order doesn't depend on the order of the input.
+	for range len(tags) {
+		sorted := slices.Clone(tags)
+		slices.SortFunc(sorted, compareTags)
+
+		names := make([]string, 0, len(sorted))
+		for _, tag := range sorted {
+			names = append(names, tag.Name)
+		}
+
+		assert.Equal(t, want, names)
+
+		tags = append(tags[1:], tags[0])
+	}
+}
@@ -0,0 +1,120 @@
+package gitsource
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+)
+
+// openLocal opens the local repository and returns the commit of the ref. HEAD is used if ref is empty.
+func openLocal(dir, ref string) (*git.Repository, *object.Commit, error) {
+	r, err := plainOpen(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	revision := plumbing.Revision(plumbing.HEAD)
+	if ref != "" {
+		revision = plumbing.Revision(ref)
+	}
+
+	commit, err := resolve(r, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, commit, nil
+}
+
+func plainOpen(dir string) (*git.Repository, error) {
+	r, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository %s: %w", dir, err)
+	}
+
+	return r, nil
+}
+
+// WalkWorktree calls fn for every file in the working tree of the repository that matches paths,
+// including uncommitted and untracked files. Files ignored by git and folders that can't contain a
+// match aren't read. A nil paths filter matches every file. Only regular files are considered.
+func WalkWorktree(r *git.Repository, paths *filter.Filter, fn func(name string, read func() ([]byte, error)) error) error {
+	w, err := r.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	patterns, err := gitignore.ReadPatterns(w.Filesystem, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read gitignore patterns: %w", err)
+	}
+
+	ignored := gitignore.NewMatcher(append(patterns, w.Excludes...))
+
+	return walkWorktree(w.Filesystem, "", paths, ignored, fn)
+}
+
+func walkWorktree(fs billy.Filesystem, dir string, paths *filter.Filter, ignored gitignore.Matcher, fn func(name string, read func() ([]byte, error)) error) error {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read folder %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		segments := strings.Split(name, "/")
+
+		switch {
+		case entry.IsDir():
+			if entry.Name() == git.GitDirName || ignored.Match(segments, true) || !paths.MayContain(name) {
+				continue
+			}
+
+			if err := walkWorktree(fs, name, paths, ignored, fn); err != nil {
+				return err
+			}
+		case entry.Mode().IsRegular():
+			if ignored.Match(segments, false) || !paths.Match(name) {
+				continue
+			}
+
+			if err := fn(name, func() ([]byte, error) {
+				return readFile(fs, name)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func readFile(fs billy.Filesystem, name string) (_ []byte, err error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,93 @@
+package gitsource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+)
+
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestOpenLocal(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	w, err := r.Worktree()
+	require.NoError(t, err)
+
+	writeTestFile(t, dir, "config/crd/a.yaml", "committed")
+	_, err = w.Add("config/crd/a.yaml")
+	require.NoError(t, err)
+
+	hash, err := w.Commit("add a", &git.CommitOptions{Author: &object.Signature{Name: "test", When: time.Unix(0, 0)}})
+	require.NoError(t, err)
+
+	_, err = r.CreateTag("v1.0.0", hash, nil)
+	require.NoError(t, err)
+
+	for _, opts := range []Options{
+		{Path: dir},
+		{Path: filepath.Join(dir, "config")},
+		{URL: "file://" + dir, Ref: "v1.0.0"},
+	} {
+		_, commit, err := Clone(opts)
+		require.NoError(t, err)
+		assert.Equal(t, hash, commit.Hash)
+	}
+
+	assert.Equal(t, dir, Options{URL: "file://" + dir}.LocalPath())
+	assert.Empty(t, Options{URL: "https://github.com/Skarlso/crd-to-sample-yaml"}.LocalPath())
+
+	// uncommitted changes only show up in the working tree.
+	writeTestFile(t, dir, "config/crd/a.yaml", "modified")
+	writeTestFile(t, dir, "config/crd/b.yaml", "untracked")
+	writeTestFile(t, dir, "config/crd/ignored.yaml", "ignored")
+	writeTestFile(t, dir, "bin/c.yaml", "ignored")
+	writeTestFile(t, dir, "docs/d.yaml", "outside")
+	writeTestFile(t, dir, ".gitignore", "bin/\nignored.yaml\n")
+
+	_, commit, err := Clone(Options{Path: dir})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config/crd/a.yaml"}, files(t, commit, nil))
+
+	r, err = Open(Options{Path: dir})
+	require.NoError(t, err)
+
+	paths, err := filter.New([]string{"config/**"}, nil)
+	require.NoError(t, err)
+
+	contents := map[string]string{}
+	require.NoError(t, WalkWorktree(r, paths, func(name string, read func() ([]byte, error)) error {
+		content, err := read()
+		contents[name] = string(content)
+
+		return err
+	}))
+
+	assert.Equal(t, map[string]string{
+		"config/crd/a.yaml": "modified",
+		"config/crd/b.yaml": "untracked",
+	}, contents)
+
+	var names []string
+	require.NoError(t, WalkWorktree(r, nil, func(name string, _ func() ([]byte, error)) error {
+		names = append(names, name)
+
+		return nil
+	}))
+	assert.Equal(t, []string{".gitignore", "config/crd/a.yaml", "config/crd/b.yaml", "docs/d.yaml"}, names)
+}
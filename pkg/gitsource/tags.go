@@ -0,0 +1,81 @@
+package gitsource
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Tag is a tag of the repository and the commit it points to.
+type Tag struct {
+	Name   string
+	Commit *object.Commit
+}
+
+// Tags returns the tags that match the glob pattern, oldest first. An empty pattern matches every tag.
+// Tags that are semantic versions come first, sorted by their version, followed by the others, sorted by
+// the time of their commit.
+func Tags(r *git.Repository, pattern string) ([]Tag, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid tag pattern %s: %w", pattern, err)
+	}
+
+	refs, err := r.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var tags []Tag
+
+	if err := refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if pattern != "" {
+			if ok, _ := path.Match(pattern, name); !ok {
+				return nil
+			}
+		}
+
+		commit, err := resolve(r, plumbing.Revision(ref.Name()))
+		if err != nil {
+			return err
+		}
+
+		tags = append(tags, Tag{Name: name, Commit: commit})
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(tags, compareTags)
+
+	return tags, nil
+}
+
+func compareTags(a, b Tag) int {
+	av, aErr := semver.NewVersion(a.Name)
+	bv, bErr := semver.NewVersion(b.Name)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		if c := av.Compare(bv); c != 0 {
+			return c
+		}
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		if c := a.Commit.Committer.When.Compare(b.Commit.Committer.When); c != 0 {
+			return c
+		}
+	}
+
+	return strings.Compare(a.Name, b.Name)
+}
@@ -0,0 +1,920 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+const (
+	markerPrefix     = "+"
+	validationMarker = "kubebuilder:validation:"
+	itemsMarker      = "items:"
+)
+
+// GoTypesParser builds schema types directly from kubebuilder annotated Go API packages
+// without running controller-gen first.
+type GoTypesParser struct {
+	fileSet  *token.FileSet
+	root     string
+	packages map[string]*goPackage // key: directory relative to the root
+}
+
+// goPackage contains the declarations and package level markers of a single API package.
+type goPackage struct {
+	dir             string
+	name            string
+	group           string
+	version         string
+	skip            bool
+	defaultOptional bool
+	types           map[string]*goType
+	order           []string
+}
+
+// goType is a type declaration together with the imports of the file that declares it.
+type goType struct {
+	spec *ast.TypeSpec
+	doc  *ast.CommentGroup
+	// markers contains the doc lines and the lines of a comment group separated from the doc by an
+	// empty line, which is where kubebuilder puts markers like `+kubebuilder:object:root`.
+	markers []string
+	imports map[string]string
+}
+
+// goScope is used to resolve type names used inside a declaration.
+type goScope struct {
+	pkg     *goPackage
+	imports map[string]string
+}
+
+// NewGoTypesParser creates a new Go types parser.
+func NewGoTypesParser() *GoTypesParser {
+	return &GoTypesParser{
+		fileSet:  token.NewFileSet(),
+		packages: make(map[string]*goPackage),
+	}
+}
+
+// ParseGoTypes parses the Go API packages in the given directory and its subdirectories and
+// returns a schema type for every kind marked with `+kubebuilder:object:root`. The group is read
+// from the `+groupName` marker or from the GroupVersion variable of the package. The version is
+// read from the `+versionName` marker or defaults to the name of the package. Kinds that exist
+// in multiple versions are returned as a single schema type with multiple versions.
+func (p *GoTypesParser) ParseGoTypes(dir string) ([]*SchemaType, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dir)
+	}
+
+	p.root = dir
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") ||
+			strings.HasPrefix(d.Name(), "zz_generated") {
+			return nil
+		}
+
+		if err := p.parseFile(path); err != nil {
+			return fmt.Errorf("failed to parse file %s: %w", path, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return p.schemaTypes()
+}
+
+func (p *GoTypesParser) parseFile(filename string) error {
+	src, err := parser.ParseFile(p.fileSet, filename, nil, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	dir, err := filepath.Rel(p.root, filepath.Dir(filename))
+	if err != nil {
+		return fmt.Errorf("failed to find relative path: %w", err)
+	}
+
+	pkg, ok := p.packages[dir]
+	if !ok {
+		pkg = &goPackage{dir: filepath.ToSlash(dir), name: src.Name.Name, types: make(map[string]*goType)}
+		p.packages[dir] = pkg
+	}
+
+	// package level markers are placed in the comments above the package clause.
+	for _, cg := range src.Comments {
+		if cg.Pos() > src.Package {
+			break
+		}
+
+		pkg.parseMarkers(commentLines(cg))
+	}
+
+	imports := make(map[string]string, len(src.Imports))
+
+	for _, imp := range src.Imports {
+		path := strings.Trim(imp.Path.Value, `"`)
+
+		name := filepath.Base(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+
+		imports[name] = path
+	}
+
+	for _, decl := range src.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				doc := s.Doc
+				if doc == nil && len(genDecl.Specs) == 1 {
+					doc = genDecl.Doc
+				}
+
+				start := genDecl.Pos()
+				if doc != nil {
+					start = doc.Pos()
+				}
+
+				lines := append(commentLines(p.precedingComment(src, start)), commentLines(doc)...)
+				pkg.types[s.Name.Name] = &goType{spec: s, doc: doc, markers: lines, imports: imports}
+				pkg.order = append(pkg.order, s.Name.Name)
+			case *ast.ValueSpec:
+				pkg.parseGroupVersion(s)
+			}
+		}
+	}
+
+	return nil
+}
+
+// precedingComment returns the comment group that ends one empty line above the given position.
+func (p *GoTypesParser) precedingComment(src *ast.File, pos token.Pos) *ast.CommentGroup {
+	line := p.fileSet.Position(pos).Line
+
+	for _, cg := range src.Comments {
+		if cg.End() < pos && p.fileSet.Position(cg.End()).Line == line-2 { //nolint:mnd // the group and an empty line
+			return cg
+		}
+	}
+
+	return nil
+}
+
+// parseMarkers reads the package level markers.
+func (pkg *goPackage) parseMarkers(lines []string) {
+	for _, m := range markers(lines) {
+		switch m.name {
+		case "groupName":
+			pkg.group = m.value
+		case "versionName":
+			pkg.version = m.value
+		case "kubebuilder:skip":
+			pkg.skip = true
+		case validationMarker + "Optional":
+			pkg.defaultOptional = true
+		case validationMarker + "Required":
+			pkg.defaultOptional = false
+		}
+	}
+}
+
+// parseGroupVersion reads the group and version from a `schema.GroupVersion{Group: "", Version: ""}`
+// variable as it's scaffolded by kubebuilder. The markers take precedence.
+func (pkg *goPackage) parseGroupVersion(spec *ast.ValueSpec) {
+	for _, value := range spec.Values {
+		lit, ok := value.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+
+		sel, ok := lit.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "GroupVersion" {
+			continue
+		}
+
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+
+			value, ok := stringLiteral(kv.Value)
+			if !ok {
+				continue
+			}
+
+			switch {
+			case key.Name == "Group" && pkg.group == "":
+				pkg.group = value
+			case key.Name == "Version" && pkg.version == "":
+				pkg.version = value
+			}
+		}
+	}
+}
+
+// schemaTypes creates a schema type for every root object of every parsed package.
+func (p *GoTypesParser) schemaTypes() ([]*SchemaType, error) {
+	dirs := make([]string, 0, len(p.packages))
+	for dir := range p.packages {
+		dirs = append(dirs, dir)
+	}
+
+	sort.Strings(dirs)
+
+	kinds := make(map[string]*SchemaType)
+
+	for _, dir := range dirs {
+		pkg := p.packages[dir]
+		if pkg.skip {
+			continue
+		}
+
+		for _, name := range pkg.order {
+			typ := pkg.types[name]
+			if !isRootObject(typ) {
+				continue
+			}
+
+			if pkg.group == "" {
+				return nil, fmt.Errorf("no group found for kind %s in %s, add a +groupName marker to the package", name, pkg.dir)
+			}
+
+			schema, err := p.rootSchema(pkg, typ)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create schema for kind %s in %s: %w", name, pkg.dir, err)
+			}
+
+			versionName := pkg.version
+			if versionName == "" {
+				versionName = pkg.name
+			}
+
+			key := pkg.group + "/" + name
+
+			schemaType, ok := kinds[key]
+			if !ok {
+				schemaType = &SchemaType{Group: pkg.group, Kind: name}
+				kinds[key] = schemaType
+			}
+
+			schemaType.Versions = append(schemaType.Versions, &CRDVersion{Name: versionName, Schema: schema})
+		}
+	}
+
+	result := make([]*SchemaType, 0, len(kinds))
+
+	for _, schemaType := range kinds {
+		// order versions from the least to the most stable one, like they are usually listed in a CRD.
+		sort.SliceStable(schemaType.Versions, func(i, j int) bool {
+			return version.CompareKubeAwareVersionStrings(schemaType.Versions[i].Name, schemaType.Versions[j].Name) < 0
+		})
+
+		result = append(result, schemaType)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Group != result[j].Group {
+			return result[i].Group < result[j].Group
+		}
+
+		return result[i].Kind < result[j].Kind
+	})
+
+	return result, nil
+}
+
+// isRootObject returns true for structs marked with `+kubebuilder:object:root` that aren't lists.
+func isRootObject(typ *goType) bool {
+	st, ok := typ.spec.Type.(*ast.StructType)
+	if !ok {
+		return false
+	}
+
+	root := false
+
+	for _, m := range markers(typ.markers) {
+		if m.name == "kubebuilder:object:root" && m.value != "false" {
+			root = true
+		}
+	}
+
+	if !root {
+		return false
+	}
+
+	for _, field := range st.Fields.List {
+		if name, _ := jsonTag(field); name == "items" && strings.HasSuffix(typ.spec.Name.Name, "List") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *GoTypesParser) rootSchema(pkg *goPackage, typ *goType) (*v1beta1.JSONSchemaProps, error) {
+	scope := goScope{pkg: pkg, imports: typ.imports}
+
+	schema, err := p.typeSchema(scope, typ.spec.Type, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Description = description(typ.doc)
+
+	if schema.Properties == nil {
+		schema.Properties = map[string]v1beta1.JSONSchemaProps{}
+	}
+
+	if _, ok := schema.Properties["metadata"]; ok {
+		schema.Properties["metadata"] = v1beta1.JSONSchemaProps{Type: typeObject}
+	}
+
+	ensureKindAndAPIVersionIsSet(schema.Properties)
+
+	return schema, nil
+}
+
+// typeSchema converts a Go type expression into a schema.
+func (p *GoTypesParser) typeSchema(scope goScope, expr ast.Expr, visiting map[string]bool) (*v1beta1.JSONSchemaProps, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return p.typeSchema(scope, t.X, visiting)
+	case *ast.Ident:
+		if schema, ok := builtinSchema(t.Name); ok {
+			return schema, nil
+		}
+
+		return p.namedSchema(scope.pkg, t.Name, visiting)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &v1beta1.JSONSchemaProps{Type: typeString, Format: "byte"}, nil
+		}
+
+		items, err := p.typeSchema(scope, t.Elt, visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		return &v1beta1.JSONSchemaProps{Type: array, Items: &v1beta1.JSONSchemaPropsOrArray{Schema: items}}, nil
+	case *ast.MapType:
+		values, err := p.typeSchema(scope, t.Value, visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		return &v1beta1.JSONSchemaProps{
+			Type:                 typeObject,
+			AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: values},
+		}, nil
+	case *ast.StructType:
+		return p.structSchema(scope, t, visiting)
+	case *ast.InterfaceType:
+		return preserveUnknownFields(), nil
+	case *ast.SelectorExpr:
+		return p.selectorSchema(scope, t, visiting)
+	default:
+		return nil, fmt.Errorf("unsupported type expression %T", expr)
+	}
+}
+
+// namedSchema resolves a type declared in the package and applies the markers of the declaration.
+func (p *GoTypesParser) namedSchema(pkg *goPackage, name string, visiting map[string]bool) (*v1beta1.JSONSchemaProps, error) {
+	typ, ok := pkg.types[name]
+	if !ok {
+		return preserveUnknownFields(), nil
+	}
+
+	key := pkg.dir + "." + name
+	if visiting[key] {
+		// recursive types can't be expanded any further.
+		return preserveUnknownFields(), nil
+	}
+
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	schema, err := p.typeSchema(goScope{pkg: pkg, imports: typ.imports}, typ.spec.Type, visiting)
+	if err != nil {
+		return nil, err
+	}
+
+	if desc := description(typ.doc); desc != "" {
+		schema.Description = desc
+	}
+
+	if err := applyMarkers(schema, typ.markers); err != nil {
+		return nil, fmt.Errorf("invalid marker on type %s: %w", name, err)
+	}
+
+	return schema, nil
+}
+
+// selectorSchema resolves types of other packages. Well known Kubernetes types have a fixed schema,
+// types of other parsed packages are resolved and everything else allows any content.
+func (p *GoTypesParser) selectorSchema(scope goScope, sel *ast.SelectorExpr, visiting map[string]bool) (*v1beta1.JSONSchemaProps, error) {
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return preserveUnknownFields(), nil
+	}
+
+	importPath := scope.imports[ident.Name]
+
+	if schema, ok := wellKnownSchema(importPath, sel.Sel.Name); ok {
+		return schema, nil
+	}
+
+	for _, pkg := range p.packages {
+		if pkg.dir != "." && strings.HasSuffix(importPath, "/"+pkg.dir) {
+			return p.namedSchema(pkg, sel.Sel.Name, visiting)
+		}
+	}
+
+	return preserveUnknownFields(), nil
+}
+
+// structSchema creates an object schema out of the fields of a struct.
+func (p *GoTypesParser) structSchema(scope goScope, st *ast.StructType, visiting map[string]bool) (*v1beta1.JSONSchemaProps, error) {
+	schema := &v1beta1.JSONSchemaProps{Type: typeObject, Properties: map[string]v1beta1.JSONSchemaProps{}}
+
+	for _, field := range st.Fields.List {
+		name, options := jsonTag(field)
+		if name == "-" {
+			continue
+		}
+
+		fieldSchema, err := p.typeSchema(scope, field.Type, visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Contains(options, "inline") || (len(field.Names) == 0 && name == "") {
+			for k, v := range fieldSchema.Properties {
+				schema.Properties[k] = v
+			}
+
+			schema.Required = append(schema.Required, fieldSchema.Required...)
+
+			continue
+		}
+
+		names := []string{name}
+		if name == "" {
+			// fields without a tag are named after the Go field, and unexported ones aren't serialized.
+			names = names[:0]
+
+			for _, ident := range field.Names {
+				if ast.IsExported(ident.Name) {
+					names = append(names, ident.Name)
+				}
+			}
+		}
+
+		if len(names) == 0 {
+			continue
+		}
+
+		lines := commentLines(field.Doc)
+		if desc := description(field.Doc); desc != "" {
+			fieldSchema.Description = desc
+		}
+
+		if err := applyMarkers(fieldSchema, lines); err != nil {
+			return nil, fmt.Errorf("invalid marker on field %s: %w", names[0], err)
+		}
+
+		for _, name := range names {
+			if fieldRequired(scope.pkg, options, lines) {
+				schema.Required = append(schema.Required, name)
+			}
+
+			schema.Properties[name] = *fieldSchema
+		}
+	}
+
+	return schema, nil
+}
+
+// fieldRequired follows the rules of controller-gen. Fields are required unless they are omitted when
+// empty or the package makes fields optional by default. Explicit markers override both.
+func fieldRequired(pkg *goPackage, options, lines []string) bool {
+	required := !pkg.defaultOptional && !slices.Contains(options, "omitempty") && !slices.Contains(options, "omitzero")
+
+	for _, m := range markers(lines) {
+		switch m.name {
+		case "optional", validationMarker + "Optional":
+			required = false
+		case "required", validationMarker + "Required":
+			required = true
+		}
+	}
+
+	return required
+}
+
+// applyMarkers applies the validation, default and example markers to a schema.
+// Markers prefixed with `items:` are applied to the items of an array.
+func applyMarkers(schema *v1beta1.JSONSchemaProps, lines []string) error {
+	for _, m := range markers(lines) {
+		target := schema
+		name := m.name
+
+		switch {
+		case strings.HasPrefix(name, validationMarker+itemsMarker):
+			if schema.Items == nil || schema.Items.Schema == nil {
+				continue
+			}
+
+			target = schema.Items.Schema
+			name = strings.TrimPrefix(name, validationMarker+itemsMarker)
+		case strings.HasPrefix(name, validationMarker):
+			name = strings.TrimPrefix(name, validationMarker)
+		}
+
+		if err := applyMarker(target, name, m.value); err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+	}
+
+	return nil
+}
+
+//nolint:gocyclo,cyclop,funlen // a flat list of markers is easier to follow.
+func applyMarker(schema *v1beta1.JSONSchemaProps, name, value string) error {
+	var err error
+
+	switch name {
+	case "Minimum":
+		schema.Minimum, err = parseFloat(value)
+	case "Maximum":
+		schema.Maximum, err = parseFloat(value)
+	case "MultipleOf":
+		schema.MultipleOf, err = parseFloat(value)
+	case "ExclusiveMinimum":
+		schema.ExclusiveMinimum = value != "false"
+	case "ExclusiveMaximum":
+		schema.ExclusiveMaximum = value != "false"
+	case "MinLength":
+		schema.MinLength, err = parseInt(value)
+	case "MaxLength":
+		schema.MaxLength, err = parseInt(value)
+	case "MinItems":
+		schema.MinItems, err = parseInt(value)
+	case "MaxItems":
+		schema.MaxItems, err = parseInt(value)
+	case "MinProperties":
+		schema.MinProperties, err = parseInt(value)
+	case "MaxProperties":
+		schema.MaxProperties, err = parseInt(value)
+	case "UniqueItems":
+		schema.UniqueItems = value != "false"
+	case "Pattern":
+		schema.Pattern = unquote(value)
+	case "Format":
+		schema.Format = unquote(value)
+	case "Type":
+		schema.Type = unquote(value)
+	case "Enum":
+		schema.Enum = nil
+		for v := range strings.SplitSeq(strings.Trim(value, "{}"), ";") {
+			schema.Enum = append(schema.Enum, v1beta1.JSON{Raw: markerJSON(schema.Type, strings.TrimSpace(v))})
+		}
+	case "XPreserveUnknownFields", "kubebuilder:pruning:PreserveUnknownFields":
+		preserve := true
+		schema.XPreserveUnknownFields = &preserve
+	case "XEmbeddedResource", "EmbeddedResource":
+		schema.XEmbeddedResource = true
+	case "XIntOrString":
+		schema.XIntOrString = true
+	case "XValidation":
+		rule, err := parseValidationRule(value)
+		if err != nil {
+			return err
+		}
+
+		schema.XValidations = append(schema.XValidations, rule)
+	case "nullable":
+		schema.Nullable = true
+	case "kubebuilder:default", "default":
+		schema.Default = &v1beta1.JSON{Raw: markerJSON(schema.Type, value)}
+	case "kubebuilder:example":
+		schema.Example = &v1beta1.JSON{Raw: markerJSON(schema.Type, value)}
+	case "listType":
+		listType := value
+		schema.XListType = &listType
+	case "listMapKey":
+		schema.XListMapKeys = append(schema.XListMapKeys, value)
+	case "mapType":
+		mapType := value
+		schema.XMapType = &mapType
+	}
+
+	return err
+}
+
+// parseValidationRule parses the arguments of an XValidation marker like `rule="self > 0",message="must be positive"`.
+func parseValidationRule(value string) (v1beta1.ValidationRule, error) {
+	rule := v1beta1.ValidationRule{}
+
+	for len(value) > 0 {
+		key, rest, ok := strings.Cut(value, "=")
+		if !ok {
+			return rule, fmt.Errorf("invalid argument %q", value)
+		}
+
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return rule, fmt.Errorf("argument %s must be a quoted string: %w", key, err)
+		}
+
+		unquoted, _ := strconv.Unquote(quoted)
+
+		switch strings.TrimSpace(key) {
+		case "rule":
+			rule.Rule = unquoted
+		case "message":
+			rule.Message = unquoted
+		case "messageExpression":
+			rule.MessageExpression = unquoted
+		case "fieldPath":
+			rule.FieldPath = unquoted
+		}
+
+		value = strings.TrimPrefix(rest[len(quoted):], ",")
+	}
+
+	return rule, nil
+}
+
+// markerJSON converts a marker value into JSON. Values that aren't valid JSON, and values of string
+// fields that aren't quoted, are treated as strings. Values in the form of `{a,b}` are lists of strings.
+func markerJSON(typ, value string) []byte {
+	value = strings.TrimSpace(value)
+
+	if json.Valid([]byte(value)) && (typ != typeString || strings.HasPrefix(value, `"`)) {
+		return []byte(value)
+	}
+
+	if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		var items []string
+		for item := range strings.SplitSeq(strings.Trim(value, "{}"), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, unquote(item))
+			}
+		}
+
+		content, _ := json.Marshal(items)
+
+		return content
+	}
+
+	content, _ := json.Marshal(unquote(value))
+
+	return content
+}
+
+type marker struct {
+	name  string
+	value string
+}
+
+// markers returns the markers found in comment lines. A marker has the form of `+name` or `+name=value`.
+func markers(lines []string) []marker {
+	var result []marker
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, markerPrefix) {
+			continue
+		}
+
+		name, value, _ := strings.Cut(strings.TrimPrefix(line, markerPrefix), "=")
+		// XValidation takes named arguments like `XValidation:rule="...",message="..."`.
+		if before, args, ok := strings.Cut(name, ":XValidation:"); ok {
+			name, value = before+":XValidation", args+"="+value
+		}
+
+		result = append(result, marker{name: strings.TrimSpace(name), value: strings.TrimSpace(value)})
+	}
+
+	return result
+}
+
+// commentLines returns the lines of a comment group keeping empty lines.
+func commentLines(cg *ast.CommentGroup) []string {
+	if cg == nil {
+		return nil
+	}
+
+	var lines []string
+
+	for _, comment := range cg.List {
+		text := comment.Text
+		if strings.HasPrefix(text, "/*") {
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+			lines = append(lines, strings.Split(text, "\n")...)
+
+			continue
+		}
+
+		lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(text, "//"), " "))
+	}
+
+	return lines
+}
+
+// description returns the comment without markers and linter directives.
+func description(cg *ast.CommentGroup) string {
+	var lines []string
+
+	for _, line := range commentLines(cg) {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, markerPrefix) || strings.HasPrefix(trimmed, "nolint") ||
+			strings.HasPrefix(trimmed, "go:") {
+			continue
+		}
+
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func jsonTag(field *ast.Field) (string, []string) {
+	if field.Tag == nil {
+		return "", nil
+	}
+
+	tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json")
+	name, options, _ := strings.Cut(tag, ",")
+
+	return name, strings.Split(options, ",")
+}
+
+func builtinSchema(name string) (*v1beta1.JSONSchemaProps, bool) {
+	switch name {
+	case "string":
+		return &v1beta1.JSONSchemaProps{Type: typeString}, true
+	case "bool":
+		return &v1beta1.JSONSchemaProps{Type: typeBoolean}, true
+	case "int32", "uint32", "int16", "uint16", "int8", "uint8", "byte":
+		return &v1beta1.JSONSchemaProps{Type: typeInteger, Format: "int32"}, true
+	case "int64", "uint64":
+		return &v1beta1.JSONSchemaProps{Type: typeInteger, Format: "int64"}, true
+	case "int", "uint":
+		return &v1beta1.JSONSchemaProps{Type: typeInteger}, true
+	case "float32", "float64":
+		return &v1beta1.JSONSchemaProps{Type: typeNumber}, true
+	case "any":
+		return preserveUnknownFields(), true
+	default:
+		return nil, false
+	}
+}
+
+const (
+	metaV1Package      = "k8s.io/apimachinery/pkg/apis/meta/v1"
+	resourcePackage    = "k8s.io/apimachinery/pkg/api/resource"
+	intOrStringPackage = "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// wellKnownSchema returns the schema of common apimachinery types the way controller-gen renders them.
+// Types of the same name in other packages aren't well known.
+func wellKnownSchema(importPath, name string) (*v1beta1.JSONSchemaProps, bool) {
+	switch importPath {
+	case resourcePackage:
+		if name != "Quantity" {
+			return nil, false
+		}
+	case intOrStringPackage:
+		if name != "IntOrString" {
+			return nil, false
+		}
+	case metaV1Package:
+		if name == "Quantity" || name == "IntOrString" {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	switch name {
+	case "Time", "MicroTime":
+		return &v1beta1.JSONSchemaProps{Type: typeString, Format: "date-time"}, true
+	case "Duration":
+		return &v1beta1.JSONSchemaProps{Type: typeString}, true
+	case "ObjectMeta", "ListMeta":
+		return &v1beta1.JSONSchemaProps{Type: typeObject}, true
+	case "TypeMeta":
+		return &v1beta1.JSONSchemaProps{Type: typeObject, Properties: map[string]v1beta1.JSONSchemaProps{
+			"apiVersion": {
+				Type:        typeString,
+				Description: "APIVersion defines the versioned schema of this representation of an object.",
+			},
+			"kind": {
+				Type:        typeString,
+				Description: "Kind is a string value representing the REST resource this object represents.",
+			},
+		}}, true
+	case "Quantity", "IntOrString":
+		return &v1beta1.JSONSchemaProps{XIntOrString: true, AnyOf: []v1beta1.JSONSchemaProps{
+			{Type: typeInteger}, {Type: typeString},
+		}}, true
+	case "Condition":
+		return conditionSchema(), true
+	default:
+		return nil, false
+	}
+}
+
+func conditionSchema() *v1beta1.JSONSchemaProps {
+	minLength := int64(1)
+
+	return &v1beta1.JSONSchemaProps{
+		Type: typeObject,
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"type":               {Type: typeString, Description: "type of condition in CamelCase or in foo.example.com/CamelCase."},
+			"status":             {Type: typeString, Enum: []v1beta1.JSON{{Raw: []byte(`"True"`)}, {Raw: []byte(`"False"`)}, {Raw: []byte(`"Unknown"`)}}},
+			"reason":             {Type: typeString, MinLength: &minLength},
+			"message":            {Type: typeString},
+			"lastTransitionTime": {Type: typeString, Format: "date-time"},
+			"observedGeneration": {Type: typeInteger, Format: "int64"},
+		},
+		Required: []string{"lastTransitionTime", "message", "reason", "status", "type"},
+	}
+}
+
+func preserveUnknownFields() *v1beta1.JSONSchemaProps {
+	preserve := true
+
+	return &v1beta1.JSONSchemaProps{Type: typeObject, XPreserveUnknownFields: &preserve}
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	value, err := strconv.Unquote(lit.Value)
+
+	return value, err == nil
+}
+
+func unquote(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+
+	return value
+}
+
+func parseFloat(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+func parseInt(value string) (*int64, error) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
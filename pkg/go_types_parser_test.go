@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoTypesParser_ParseGoTypes(t *testing.T) {
+	schemaTypes, err := NewGoTypesParser().ParseGoTypes(filepath.Join("testdata", "goapi"))
+	require.NoError(t, err)
+	require.Len(t, schemaTypes, 1)
+
+	runner := schemaTypes[0]
+	assert.Equal(t, "test.example.com", runner.Group)
+	assert.Equal(t, "Runner", runner.Kind)
+	require.Len(t, runner.Versions, 2)
+	assert.Equal(t, "v1alpha1", runner.Versions[0].Name)
+	assert.Equal(t, "v1", runner.Versions[1].Name)
+
+	schema := runner.Versions[1].Schema
+	assert.Equal(t, "Runner runs things.", schema.Description)
+	assert.Contains(t, schema.Properties, "apiVersion")
+	assert.Contains(t, schema.Properties, "kind")
+	assert.Equal(t, "object", schema.Properties["metadata"].Type)
+
+	spec := schema.Properties["spec"]
+	assert.ElementsMatch(t, []string{"image", "secret"}, spec.Required)
+	assert.NotContains(t, spec.Properties, "Internal")
+
+	image := spec.Properties["image"]
+	assert.Equal(t, "Image to run.\n\nMust be a fully qualified reference.", image.Description)
+	assert.Equal(t, int64(1), *image.MinLength)
+	assert.Equal(t, "^[a-z./:-]+$", image.Pattern)
+
+	mode := spec.Properties["mode"]
+	assert.Equal(t, "Mode of the runner.", mode.Description)
+	assert.JSONEq(t, `"Fast"`, string(mode.Default.Raw))
+	require.Len(t, mode.Enum, 2)
+	assert.JSONEq(t, `"Slow"`, string(mode.Enum[1].Raw))
+
+	replicas := spec.Properties["replicas"]
+	assert.Equal(t, "integer", replicas.Type)
+	assert.Equal(t, "int32", replicas.Format)
+	assert.InDelta(t, 1, *replicas.Minimum, 0)
+	assert.InDelta(t, 10, *replicas.Maximum, 0)
+
+	args := spec.Properties["args"]
+	assert.Equal(t, "array", args.Type)
+	assert.Equal(t, int64(5), *args.MaxItems)
+	assert.Equal(t, int64(2), *args.Items.Schema.MinLength)
+
+	assert.Equal(t, "string", spec.Properties["labels"].AdditionalProperties.Schema.Type)
+	assert.True(t, spec.Properties["port"].XIntOrString)
+
+	secret := spec.Properties["secret"]
+	assert.Equal(t, "SecretReference points to a secret in the same namespace.", secret.Description)
+	assert.Equal(t, []string{"name"}, secret.Required)
+	require.Len(t, secret.XValidations, 1)
+	assert.Equal(t, "self.size() > 0", secret.XValidations[0].Rule)
+	assert.Equal(t, "must not be empty", secret.XValidations[0].Message)
+
+	status := schema.Properties["status"]
+	assert.Equal(t, "date-time", status.Properties["lastRun"].Format)
+	assert.Contains(t, status.Properties["conditions"].Items.Schema.Properties, "lastTransitionTime")
+}
+
+func TestGoTypesParser_GenerateSample(t *testing.T) {
+	schemaTypes, err := NewGoTypesParser().ParseGoTypes(filepath.Join("testdata", "goapi"))
+	require.NoError(t, err)
+
+	runner := schemaTypes[0]
+	version := runner.Versions[1]
+	parser := NewParser(runner.Group, runner.Kind, false, true, true)
+
+	var output bytes.Buffer
+	require.NoError(t, parser.ParseProperties(version.Name, &output, version.Schema.Properties, RootRequiredFields))
+
+	assert.Equal(t, `apiVersion: test.example.com/v1
+kind: Runner
+metadata: {}
+spec:
+  image: string
+  secret:
+    name: string
+status: {}
+`, output.String())
+}
+
+func TestGoTypesParser_MissingGroup(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types.go"), []byte(`package v1
+
+// +kubebuilder:object:root=true
+type Thing struct{}
+`), 0o600))
+
+	_, err := NewGoTypesParser().ParseGoTypes(dir)
+	require.ErrorContains(t, err, "no group found for kind Thing")
+}
+
+func TestGoTypesParser_Fields(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types.go"), []byte(`// +groupName=test.example.com
+package v1
+
+import (
+	"example.com/clock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+type Thing struct {
+	Spec ThingSpec `+"`json:\"spec\"`"+`
+}
+
+type ThingSpec struct {
+	Width, Height int32
+	internal      string
+	Started       metav1.Time
+	Ticked        clock.Time
+}
+`), 0o600))
+
+	schemaTypes, err := NewGoTypesParser().ParseGoTypes(dir)
+	require.NoError(t, err)
+	require.Len(t, schemaTypes, 1)
+
+	spec := schemaTypes[0].Versions[0].Schema.Properties["spec"]
+	assert.ElementsMatch(t, []string{"Width", "Height", "Started", "Ticked"}, spec.Required)
+	assert.NotContains(t, spec.Properties, "internal")
+	assert.Equal(t, "int32", spec.Properties["Height"].Format)
+	assert.Equal(t, "date-time", spec.Properties["Started"].Format)
+	assert.True(t, *spec.Properties["Ticked"].XPreserveUnknownFields, "types of other packages aren't well known")
+}
@@ -0,0 +1,107 @@
+// Package helm renders Helm charts offline so the CRDs they contain can be extracted
+// from the real manifests instead of from the raw templates.
+package helm
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/getter"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+)
+
+const (
+	releaseName      = "cty"
+	releaseNamespace = "default"
+)
+
+// Manifest is a single file produced by rendering a chart.
+type Manifest struct {
+	Name    string
+	Content []byte
+}
+
+// Render loads a chart from a folder or a packaged archive and renders it with the Helm engine
+// using the chart's default values merged with the given values files. No cluster is contacted.
+// The result contains the files of the `crds` folders of the chart and its dependencies, followed
+// by every rendered template, sorted by name.
+func Render(chartPath string, valueFiles []string) ([]Manifest, error) {
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %s: %w", chartPath, err)
+	}
+
+	return render(chrt, chartPath, valueFiles)
+}
+
+// RenderArchive works like Render but reads a packaged chart from r. The name identifies the chart in errors.
+func RenderArchive(r io.Reader, name string, valueFiles []string) ([]Manifest, error) {
+	chrt, err := loader.LoadArchive(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %s: %w", name, err)
+	}
+
+	return render(chrt, name, valueFiles)
+}
+
+func render(chrt *chart.Chart, name string, valueFiles []string) ([]Manifest, error) {
+	opts := &values.Options{ValueFiles: valueFiles}
+
+	vals, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge values: %w", err)
+	}
+
+	if err := chartutil.ProcessDependenciesWithMerge(chrt, vals); err != nil {
+		return nil, fmt.Errorf("failed to process chart dependencies: %w", err)
+	}
+
+	renderValues, err := chartutil.ToRenderValues(chrt, vals, chartutil.ReleaseOptions{
+		Name:      releaseName,
+		Namespace: releaseNamespace,
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct render values: %w", err)
+	}
+
+	rendered, err := engine.Render(chrt, renderValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart %s: %w", name, err)
+	}
+
+	manifests := make([]Manifest, 0, len(rendered))
+
+	for _, crd := range chrt.CRDObjects() {
+		manifests = append(manifests, Manifest{Name: crd.Filename, Content: crd.File.Data})
+	}
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := rendered[name]
+		// skip partials, notes and templates that rendered into nothing.
+		if strings.HasPrefix(path.Base(name), "_") || !filter.HasExtension(name) || strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		manifests = append(manifests, Manifest{Name: name, Content: []byte(content)})
+	}
+
+	return manifests, nil
+}
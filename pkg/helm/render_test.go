@@ -0,0 +1,46 @@
+package helm
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderWithDefaultValues(t *testing.T) {
+	manifests, err := Render(filepath.Join("testdata", "chart"), nil)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(manifests))
+	for _, m := range manifests {
+		names = append(names, m.Name)
+	}
+
+	assert.Equal(t, []string{
+		"test-operator/crds/static.yaml",
+		"test-operator/templates/configmap.yaml",
+		"test-operator/templates/crd.yaml",
+	}, names)
+	assert.Contains(t, string(manifests[2].Content), "- Fast\n")
+	assert.Contains(t, string(manifests[2].Content), "- Slow\n")
+	assert.Contains(t, string(manifests[2].Content), "app.kubernetes.io/version: 1.0.0")
+}
+
+func TestRenderWithValuesFiles(t *testing.T) {
+	manifests, err := Render(filepath.Join("testdata", "chart"), []string{filepath.Join("testdata", "modes-values.yaml")})
+	require.NoError(t, err)
+	require.Len(t, manifests, 3)
+	assert.Contains(t, string(manifests[2].Content), "- Turbo\n")
+	assert.NotContains(t, string(manifests[2].Content), "- Fast\n")
+
+	manifests, err = Render(filepath.Join("testdata", "chart"), []string{filepath.Join("testdata", "disabled-values.yaml")})
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	assert.Equal(t, "test-operator/crds/static.yaml", manifests[0].Name)
+}
+
+func TestRenderMissingChart(t *testing.T) {
+	_, err := Render(filepath.Join("testdata", "missing"), nil)
+	require.ErrorContains(t, err, "failed to load chart")
+}
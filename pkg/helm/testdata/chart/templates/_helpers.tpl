@@ -0,0 +1,4 @@
+{{- define "test-operator.labels" -}}
+app.kubernetes.io/name: {{ .Chart.Name }}
+app.kubernetes.io/version: {{ .Chart.AppVersion }}
+{{- end }}
@@ -0,0 +1,327 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+// jsonSchemaPassThrough are keywords that have the same meaning in JSONSchemaProps.
+var jsonSchemaPassThrough = []string{
+	"title", "description", "format", "pattern", "default", "enum", "required", "nullable", "multipleOf",
+	"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems", "uniqueItems",
+	"minProperties", "maxProperties",
+}
+
+// jsonSchemaIgnored are keywords that are dropped without a warning because they don't change the schema.
+var jsonSchemaIgnored = []string{"$comment", "$anchor", "$defs", "definitions", "$vocabulary", "$schema", "$id"}
+
+// jsonSchemaConverter converts a JSON Schema document into the subset supported by JSONSchemaProps.
+type jsonSchemaConverter struct {
+	root   any
+	source string
+	log    io.Writer
+}
+
+// ParseJSONSchema converts a draft-07 or 2020-12 JSON Schema document, in JSON or YAML, into JSONSchemaProps.
+// Local references are inlined, `const` becomes a single value enum, `examples` becomes an example, a `null`
+// type makes the property nullable and numeric exclusive bounds become a boundary with an exclusive flag.
+// Tuples defined with `prefixItems` or an items list become a list of item schemas. Keywords that have no
+// equivalent, like `if` or `unevaluatedProperties`, are dropped and a warning is written to log.
+func ParseJSONSchema(content []byte, source string, log io.Writer) (*v1beta1.JSONSchemaProps, error) {
+	var root any
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON schema %s: %w", source, err)
+	}
+
+	c := &jsonSchemaConverter{root: root, source: source, log: log}
+
+	converted, err := c.convert(root, "#", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := toJSONSchemaProps(converted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert JSON schema %s: %w", source, err)
+	}
+
+	if m, ok := root.(map[string]any); ok {
+		schema.ID, _ = m["$id"].(string)
+		if s, ok := m["$schema"].(string); ok {
+			schema.Schema = v1beta1.JSONSchemaURL(s)
+		}
+	}
+
+	return schema, nil
+}
+
+// convert converts a single schema. The pointer is the location of the schema in the document and
+// refs contains the references that are being resolved to detect recursion.
+//
+//nolint:gocyclo,cyclop,funlen // a flat list of keywords is easier to follow.
+func (c *jsonSchemaConverter) convert(node any, pointer string, refs []string) (map[string]any, error) {
+	if b, ok := node.(bool); ok {
+		if !b {
+			c.warn(pointer, "the false schema can't be represented and allows any content")
+		}
+
+		return map[string]any{"x-kubernetes-preserve-unknown-fields": true}, nil
+	}
+
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid schema at %s in %s: expected an object or a boolean but got %T", pointer, c.source, node)
+	}
+
+	result := map[string]any{}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := c.resolveRef(ref, pointer, refs)
+		if err != nil {
+			return nil, err
+		}
+
+		// siblings of a reference are allowed since 2019-09 and refine the referenced schema.
+		maps.Copy(result, resolved)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(schema)) {
+		value := schema[key]
+		at := pointer + "/" + escapePointer(key)
+
+		switch {
+		case key == "$ref", slices.Contains(jsonSchemaIgnored, key):
+		case slices.Contains(jsonSchemaPassThrough, key), strings.HasPrefix(key, "x-kubernetes-"):
+			result[key] = value
+		case key == "type":
+			c.convertType(result, value, at)
+		case key == "const":
+			result["enum"] = []any{value}
+		case key == "examples":
+			if examples, ok := value.([]any); ok && len(examples) > 0 {
+				result["example"] = examples[0]
+			}
+		case key == "example":
+			result["example"] = value
+		case key == "exclusiveMinimum", key == "exclusiveMaximum":
+			bound := "minimum"
+			if key == "exclusiveMaximum" {
+				bound = "maximum"
+			}
+
+			// draft-04 uses a flag, later drafts use the boundary itself.
+			if _, flag := value.(bool); flag {
+				result[key] = value
+			} else {
+				result[bound] = value
+				result[key] = true
+			}
+		case key == "properties", key == "patternProperties":
+			properties, err := c.convertMap(value, at, refs)
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = properties
+		case key == "items":
+			// an items list is a tuple in draft-07, and describes the items after prefixItems in 2020-12.
+			if list, ok := value.([]any); ok {
+				items, err := c.convertList(list, at, refs)
+				if err != nil {
+					return nil, err
+				}
+
+				result["items"] = items
+
+				continue
+			}
+
+			if _, tuple := schema["prefixItems"]; tuple {
+				if b, ok := value.(bool); ok {
+					result["additionalItems"] = b
+
+					continue
+				}
+
+				additional, err := c.convert(value, at, refs)
+				if err != nil {
+					return nil, err
+				}
+
+				result["additionalItems"] = additional
+
+				continue
+			}
+
+			items, err := c.convert(value, at, refs)
+			if err != nil {
+				return nil, err
+			}
+
+			result["items"] = items
+		case key == "prefixItems":
+			list, _ := value.([]any)
+
+			items, err := c.convertList(list, at, refs)
+			if err != nil {
+				return nil, err
+			}
+
+			result["items"] = items
+		case key == "additionalProperties", key == "additionalItems", key == "not":
+			if b, ok := value.(bool); ok && key != "not" {
+				result[key] = b
+
+				continue
+			}
+
+			converted, err := c.convert(value, at, refs)
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = converted
+		case key == "allOf", key == "anyOf", key == "oneOf":
+			list, _ := value.([]any)
+
+			converted, err := c.convertList(list, at, refs)
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = converted
+		default:
+			c.warn(at, "unsupported keyword "+key+" was dropped")
+		}
+	}
+
+	return result, nil
+}
+
+// convertType maps the type keyword. A list of types is reduced to a single type and a nullable flag.
+func (c *jsonSchemaConverter) convertType(result map[string]any, value any, pointer string) {
+	var types []string
+
+	switch t := value.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	if slices.Contains(types, "null") {
+		result["nullable"] = true
+		types = slices.DeleteFunc(types, func(s string) bool { return s == "null" })
+	}
+
+	slices.Sort(types)
+
+	switch {
+	case len(types) == 1:
+		result["type"] = types[0]
+	case slices.Equal(types, []string{typeInteger, typeString}):
+		result["x-kubernetes-int-or-string"] = true
+	case len(types) > 1:
+		c.warn(pointer, "multiple types "+strings.Join(types, ", ")+" can't be represented and allow any content")
+		result["x-kubernetes-preserve-unknown-fields"] = true
+	}
+}
+
+func (c *jsonSchemaConverter) convertMap(value any, pointer string, refs []string) (map[string]any, error) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid value at %s in %s: expected an object but got %T", pointer, c.source, value)
+	}
+
+	result := make(map[string]any, len(m))
+
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		converted, err := c.convert(m[k], pointer+"/"+escapePointer(k), refs)
+		if err != nil {
+			return nil, err
+		}
+
+		result[k] = converted
+	}
+
+	return result, nil
+}
+
+func (c *jsonSchemaConverter) convertList(list []any, pointer string, refs []string) ([]any, error) {
+	result := make([]any, 0, len(list))
+
+	for i, v := range list {
+		converted, err := c.convert(v, pointer+"/"+strconv.Itoa(i), refs)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, converted)
+	}
+
+	return result, nil
+}
+
+// resolveRef converts the schema a local reference points to. Recursive references and references
+// to other documents allow any content.
+func (c *jsonSchemaConverter) resolveRef(ref, pointer string, refs []string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		c.warn(pointer, "reference "+ref+" to another document is not supported and allows any content")
+
+		return map[string]any{"x-kubernetes-preserve-unknown-fields": true}, nil
+	}
+
+	if slices.Contains(refs, ref) {
+		return map[string]any{"type": typeObject, "x-kubernetes-preserve-unknown-fields": true}, nil
+	}
+
+	target := c.root
+
+	for segment := range strings.SplitSeq(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if segment == "" {
+			continue
+		}
+
+		segment = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+
+		switch t := target.(type) {
+		case map[string]any:
+			target = t[segment]
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, fmt.Errorf("reference %s at %s in %s can't be resolved", ref, pointer, c.source)
+			}
+
+			target = t[i]
+		default:
+			target = nil
+		}
+
+		if target == nil {
+			return nil, fmt.Errorf("reference %s at %s in %s can't be resolved", ref, pointer, c.source)
+		}
+	}
+
+	return c.convert(target, ref, append(refs, ref))
+}
+
+func (c *jsonSchemaConverter) warn(pointer, message string) {
+	_, _ = fmt.Fprintf(c.log, "warning: %s: %s at %s\n", c.source, message, pointer)
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONSchema(t *testing.T) {
+	content, err := os.ReadFile("testdata/json_schema_app_config.json")
+	require.NoError(t, err)
+
+	log := &bytes.Buffer{}
+	schema, err := ParseJSONSchema(content, "app-config.json", log)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/app-config.schema.json", schema.ID)
+	assert.Equal(t, "Configuration of the app.", schema.Description)
+	assert.Equal(t, []string{"name", "server"}, schema.Required)
+
+	assert.JSONEq(t, `"my-app"`, string(schema.Properties["name"].Example.Raw))
+	require.Len(t, schema.Properties["mode"].Enum, 1)
+	assert.JSONEq(t, `"production"`, string(schema.Properties["mode"].Enum[0].Raw))
+
+	replicas := schema.Properties["replicas"]
+	assert.InDelta(t, 0, *replicas.Minimum, 0)
+	assert.True(t, replicas.ExclusiveMinimum)
+
+	assert.Equal(t, "string", schema.Properties["owner"].Type)
+	assert.True(t, schema.Properties["owner"].Nullable)
+	assert.True(t, schema.Properties["port"].XIntOrString)
+
+	server := schema.Properties["server"]
+	assert.Equal(t, "The server to run.", server.Description)
+	assert.JSONEq(t, `"localhost"`, string(server.Properties["host"].Default.Raw))
+
+	point := schema.Properties["point"]
+	assert.Len(t, point.Items.JSONSchemas, 2)
+	assert.False(t, point.AdditionalItems.Allows)
+
+	children := schema.Properties["tree"].Properties["children"].Items.Schema
+	require.NotNil(t, children.XPreserveUnknownFields)
+	assert.True(t, *children.XPreserveUnknownFields)
+
+	assert.Equal(t, "string", schema.Properties["settings"].AdditionalProperties.Schema.Type)
+
+	assert.Equal(t, `warning: app-config.json: unsupported keyword propertyNames was dropped at #/properties/settings/propertyNames
+warning: app-config.json: unsupported keyword if was dropped at #/properties/tls/if
+warning: app-config.json: unsupported keyword then was dropped at #/properties/tls/then
+`, log.String())
+}
+
+func TestParseJSONSchemaDraft07(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(`
+$schema: http://json-schema.org/draft-07/schema#
+type: object
+definitions:
+  tag:
+    type: string
+    pattern: ^v[0-9]+$
+properties:
+  tags:
+    type: array
+    items:
+      $ref: '#/definitions/tag'
+  tuple:
+    type: array
+    items:
+      - type: string
+      - type: integer
+  limit:
+    type: number
+    minimum: 1
+    exclusiveMaximum: 100
+  external:
+    $ref: other.json#/definitions/thing
+`), "draft07.yaml", &bytes.Buffer{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "^v[0-9]+$", schema.Properties["tags"].Items.Schema.Pattern)
+	assert.Len(t, schema.Properties["tuple"].Items.JSONSchemas, 2)
+	assert.InDelta(t, 100, *schema.Properties["limit"].Maximum, 0)
+	assert.True(t, schema.Properties["limit"].ExclusiveMaximum)
+	assert.True(t, *schema.Properties["external"].XPreserveUnknownFields)
+}
+
+func TestParseJSONSchemaUnresolvedReference(t *testing.T) {
+	_, err := ParseJSONSchema([]byte(`{"properties": {"a": {"$ref": "#/$defs/missing"}}}`), "broken.json", &bytes.Buffer{})
+	require.ErrorContains(t, err, "reference #/$defs/missing at #/properties/a in broken.json can't be resolved")
+}
+
+func TestParseJSONSchemaArrayWithoutItems(t *testing.T) {
+	content := []byte(`{"type":"object","properties":{"tags":{"type":"array"},"name":{"type":"string"}}}`)
+
+	schema, err := ParseJSONSchema(content, "arr.json", &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Nil(t, schema.Properties["tags"].Items)
+
+	crd := &SchemaType{Kind: "AppConfig", Versions: []*CRDVersion{{Name: "v1", Schema: schema}}}
+
+	output := &bytes.Buffer{}
+	require.NoError(t, Generate(crd, &WriteNoOpCloser{w: output}, false, false, true))
+	assert.Contains(t, output.String(), "tags: []\n")
+
+	_, err = parseCRD(schema.Properties, "v1", false, "", "AppConfig", nil, 0)
+	require.NoError(t, err)
+}
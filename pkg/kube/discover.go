@@ -0,0 +1,141 @@
+// Package kube discovers CRDs, or other resources that contain an openAPIV3Schema, installed in a cluster.
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+// pageSize is the number of resources fetched per list request.
+const pageSize = 250
+
+// Selector selects the resources to discover. Every set field must match.
+type Selector struct {
+	// Name is the name of a resource or a glob, like `*.monitoring.coreos.com`. `*` or empty selects all of them.
+	Name string
+	// Group is the API group the resources define in spec.group.
+	Group string
+	// LabelSelector is a Kubernetes label selector, like `app.kubernetes.io/part-of=prometheus`.
+	LabelSelector string
+}
+
+// Config returns the configuration of a cluster. The kubeconfig defaults to KUBECONFIG or ~/.kube/config and the
+// context to the current context. If there is no kubeconfig and the process runs in a pod, the in-cluster
+// configuration is used.
+func Config(kubeconfig, kubeContext string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubernetes config: %w", err)
+	}
+
+	return config, nil
+}
+
+// Discover lists the resources and returns the schema types of the ones matching the selector, sorted by name.
+// The resources are fetched with list requests, a page at a time, instead of one request per resource.
+func Discover(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, selector Selector) ([]*pkg.SchemaType, error) {
+	if _, err := path.Match(selector.Name, ""); err != nil {
+		return nil, fmt.Errorf("invalid name glob %s: %w", selector.Name, err)
+	}
+
+	opts := metav1.ListOptions{LabelSelector: selector.LabelSelector, Limit: pageSize}
+	if selector.Name != "" && !isGlob(selector.Name) {
+		opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", selector.Name).String()
+	}
+
+	var items []unstructured.Unstructured
+
+	for {
+		list, err := client.Resource(gvr).List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.String(), err)
+		}
+
+		items = append(items, list.Items...)
+
+		if list.GetContinue() == "" {
+			break
+		}
+
+		opts.Continue = list.GetContinue()
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].GetName() < items[j].GetName()
+	})
+
+	var (
+		result []*pkg.SchemaType
+		errs   []error
+	)
+
+	for _, item := range items {
+		if !selector.matches(&item) {
+			continue
+		}
+
+		schemaTypes, err := extract(&item)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to extract schema type from %s: %w", item.GetName(), err))
+
+			continue
+		}
+
+		result = append(result, schemaTypes...)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+func (s Selector) matches(obj *unstructured.Unstructured) bool {
+	if s.Name != "" {
+		if ok, _ := path.Match(s.Name, obj.GetName()); !ok {
+			return false
+		}
+	}
+
+	if s.Group != "" {
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		if group != s.Group {
+			return false
+		}
+	}
+
+	return true
+}
+
+func extract(obj *unstructured.Unstructured) ([]*pkg.SchemaType, error) {
+	if pkg.IsCompositeResourceDefinition(obj) {
+		return pkg.ExtractCompositeSchemaTypes(obj)
+	}
+
+	schemaType, err := pkg.ExtractSchemaType(obj)
+	if err != nil || schemaType == nil {
+		return nil, err
+	}
+
+	return []*pkg.SchemaType{schemaType}, nil
+}
+
+func isGlob(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
@@ -0,0 +1,135 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+func crd(name, group, kind string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": name},
+		"spec": map[string]any{
+			"group": group,
+			"names": map[string]any{"kind": kind},
+			"versions": []any{
+				map[string]any{
+					"name": "v1",
+					"schema": map[string]any{"openAPIV3Schema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"spec": map[string]any{"type": "object"}},
+					}},
+				},
+			},
+		},
+	}}
+	obj.SetLabels(labels)
+
+	return obj
+}
+
+func TestDiscover(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdGVR: "CustomResourceDefinitionList"},
+		crd("servicemonitors.monitoring.coreos.com", "monitoring.coreos.com", "ServiceMonitor", map[string]string{"app": "prometheus"}),
+		crd("prometheuses.monitoring.coreos.com", "monitoring.coreos.com", "Prometheus", map[string]string{"app": "prometheus"}),
+		crd("certificates.cert-manager.io", "cert-manager.io", "Certificate", map[string]string{"app": "cert-manager"}),
+		crd("issuers.cert-manager.io", "cert-manager.io", "Issuer", nil),
+	)
+
+	testCases := []struct {
+		name     string
+		selector Selector
+		kinds    []string
+	}{
+		{name: "all", selector: Selector{Name: "*"}, kinds: []string{"Certificate", "Issuer", "Prometheus", "ServiceMonitor"}},
+		{name: "empty selector", selector: Selector{}, kinds: []string{"Certificate", "Issuer", "Prometheus", "ServiceMonitor"}},
+		{name: "name", selector: Selector{Name: "issuers.cert-manager.io"}, kinds: []string{"Issuer"}},
+		{name: "glob", selector: Selector{Name: "*.cert-manager.io"}, kinds: []string{"Certificate", "Issuer"}},
+		{name: "group", selector: Selector{Group: "monitoring.coreos.com"}, kinds: []string{"Prometheus", "ServiceMonitor"}},
+		{name: "label selector", selector: Selector{LabelSelector: "app in (prometheus, cert-manager)"}, kinds: []string{"Certificate", "Prometheus", "ServiceMonitor"}},
+		{name: "glob and label selector", selector: Selector{Name: "s*", LabelSelector: "app=prometheus"}, kinds: []string{"ServiceMonitor"}},
+		{name: "no match", selector: Selector{Group: "example.com"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schemaTypes, err := Discover(t.Context(), client, crdGVR, tc.selector)
+			require.NoError(t, err)
+
+			var kinds []string
+			for _, st := range schemaTypes {
+				kinds = append(kinds, st.Kind)
+			}
+
+			assert.Equal(t, tc.kinds, kinds)
+		})
+	}
+}
+
+func TestDiscoverCompositeResourceDefinitions(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositeresourcedefinitions"}
+
+	xrd := crd("xdatabases.example.org", "example.org", "XDatabase", nil)
+	xrd.SetAPIVersion("apiextensions.crossplane.io/v1")
+	xrd.SetKind("CompositeResourceDefinition")
+	require.NoError(t, unstructured.SetNestedField(xrd.Object, map[string]any{"kind": "Database"}, "spec", "claimNames"))
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CompositeResourceDefinitionList"}, xrd)
+
+	schemaTypes, err := Discover(t.Context(), client, gvr, Selector{})
+	require.NoError(t, err)
+	require.Len(t, schemaTypes, 2)
+	assert.Equal(t, "XDatabase", schemaTypes[0].Kind)
+	assert.Equal(t, "Database", schemaTypes[1].Kind)
+}
+
+func TestDiscoverInvalidGlob(t *testing.T) {
+	_, err := Discover(t.Context(), fake.NewSimpleDynamicClient(runtime.NewScheme()), crdGVR, Selector{Name: "[a"})
+	require.ErrorContains(t, err, "invalid name glob [a")
+}
+
+func TestConfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+current-context: first
+clusters:
+  - name: first
+    cluster:
+      server: https://first.example.com
+  - name: second
+    cluster:
+      server: https://second.example.com
+contexts:
+  - name: first
+    context:
+      cluster: first
+  - name: second
+    context:
+      cluster: second
+`), 0o600))
+
+	config, err := Config(kubeconfig, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://first.example.com", config.Host)
+
+	config, err = Config(kubeconfig, "second")
+	require.NoError(t, err)
+	assert.Equal(t, "https://second.example.com", config.Host)
+
+	_, err = Config(kubeconfig, "missing")
+	require.Error(t, err)
+}
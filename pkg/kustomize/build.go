@@ -0,0 +1,27 @@
+// Package kustomize builds kustomizations in-process so CRDs can be extracted from the
+// resources that actually get applied instead of from the raw base files.
+package kustomize
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Build runs kustomize on the given directory and returns the resulting resources as a multi-document YAML.
+func Build(dir string) ([]byte, error) {
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+
+	resources, err := k.Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kustomization %s: %w", dir, err)
+	}
+
+	content, err := resources.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert kustomization %s to yaml: %w", dir, err)
+	}
+
+	return content, nil
+}
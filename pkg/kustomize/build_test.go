@@ -0,0 +1,24 @@
+package kustomize
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildOverlay(t *testing.T) {
+	content, err := Build(filepath.Join("testdata", "overlay"))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(content), "app.kubernetes.io/part-of: test-operator")
+	assert.Contains(t, string(content), "strategy: Webhook")
+	assert.Contains(t, string(content), "- Fast\n")
+	assert.Contains(t, string(content), "- Slow\n")
+}
+
+func TestBuildMissingKustomization(t *testing.T) {
+	_, err := Build(filepath.Join("testdata", "missing"))
+	require.ErrorContains(t, err, "failed to build kustomization")
+}
@@ -0,0 +1,226 @@
+// Package oci pulls OCI artifacts from registries or local image layouts and extracts the
+// manifests contained in their layers.
+package oci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/archive"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/helm"
+)
+
+const (
+	// HelmChartMediaType is the media type of a packaged Helm chart layer.
+	HelmChartMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+	// refNameAnnotation names a manifest in the index of an image layout.
+	refNameAnnotation = "org.opencontainers.image.ref.name"
+	// titleAnnotation contains the file name of a layer pushed as a single file, for example by oras.
+	titleAnnotation = "org.opencontainers.image.title"
+	// layoutFile marks a folder as an OCI image layout.
+	layoutFile = "oci-layout"
+)
+
+// File is a manifest extracted from a layer.
+type File struct {
+	// Layer is the digest of the layer that contains the file.
+	Layer string
+	// Name is the path of the file in the layer.
+	Name    string
+	Content []byte
+	// Rendered is true for manifests rendered from a Helm chart. Those don't contain templates anymore.
+	Rendered bool
+}
+
+// Pull fetches the artifact and returns the YAML and JSON files of its layers. The reference is either
+// a registry reference, like `ghcr.io/org/crds:v1.0.0`, or a local image layout folder, optionally
+// followed by `:<ref name>` to select a manifest. Registry credentials are read from the docker config.
+// Helm chart layers are rendered with their default values, tar layers are unpacked and single file
+// layers are used as they are. Other layers are ignored.
+func Pull(ref string) ([]File, error) {
+	img, err := image(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layers of %s: %w", ref, err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest of %s: %w", ref, err)
+	}
+
+	var files []File
+
+	for i, layer := range layers {
+		var title string
+		if i < len(manifest.Layers) {
+			title = manifest.Layers[i].Annotations[titleAnnotation]
+		}
+
+		layerFiles, err := extract(layer, title)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, layerFiles...)
+	}
+
+	return files, nil
+}
+
+// image resolves the reference to a local image layout or a remote image.
+func image(ref string) (v1.Image, error) {
+	if dir, refName, ok := layoutPath(ref); ok {
+		return layoutImage(dir, refName)
+	}
+
+	reference, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reference %s: %w", ref, err)
+	}
+
+	img, err := remote.Image(reference, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s: %w", ref, err)
+	}
+
+	return img, nil
+}
+
+// layoutPath returns the folder and the optional ref name if the reference points to an image layout.
+func layoutPath(ref string) (string, string, bool) {
+	if isLayout(ref) {
+		return ref, "", true
+	}
+
+	if i := strings.LastIndex(ref, ":"); i > 0 && isLayout(ref[:i]) {
+		return ref[:i], ref[i+1:], true
+	}
+
+	return "", "", false
+}
+
+func isLayout(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, layoutFile))
+
+	return err == nil
+}
+
+// layoutImage selects an image from the index of the layout. Without a ref name the layout must contain
+// a single manifest.
+func layoutImage(dir, refName string) (v1.Image, error) {
+	p, err := layout.FromPath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image layout %s: %w", dir, err)
+	}
+
+	index, err := p.ImageIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index of %s: %w", dir, err)
+	}
+
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index manifest of %s: %w", dir, err)
+	}
+
+	var names []string
+
+	for _, desc := range indexManifest.Manifests {
+		descName := desc.Annotations[refNameAnnotation]
+		if (refName == "" && len(indexManifest.Manifests) == 1) || (refName != "" && descName == refName) {
+			img, err := index.Image(desc.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read image %s from %s: %w", desc.Digest, dir, err)
+			}
+
+			return img, nil
+		}
+
+		names = append(names, descName)
+	}
+
+	if refName == "" {
+		return nil, fmt.Errorf("image layout %s contains %d manifests, select one with %s:<ref name> from: %s",
+			dir, len(indexManifest.Manifests), dir, strings.Join(names, ", "))
+	}
+
+	return nil, fmt.Errorf("no manifest with ref name %s found in %s", refName, dir)
+}
+
+// extract returns the files of a single layer based on its media type.
+func extract(layer v1.Layer, title string) ([]File, error) {
+	digest, err := layer.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layer digest: %w", err)
+	}
+
+	mediaType, err := layer.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media type of layer %s: %w", digest, err)
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layer %s: %w", digest, err)
+	}
+
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	prefix := digest.String()
+
+	switch {
+	case string(mediaType) == HelmChartMediaType:
+		manifests, err := helm.RenderArchive(rc, prefix, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		files := make([]File, 0, len(manifests))
+		for _, m := range manifests {
+			files = append(files, File{Layer: prefix, Name: m.Name, Content: m.Content, Rendered: true})
+		}
+
+		return files, nil
+	case title != "" && filter.HasExtension(title):
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layer %s: %w", digest, err)
+		}
+
+		return []File{{Layer: prefix, Name: title, Content: content}}, nil
+	case strings.Contains(string(mediaType), ".tar"):
+		var files []File
+
+		err := archive.Walk(rc, filter.HasExtension, func(name string, content []byte) error {
+			files = append(files, File{Layer: prefix, Name: name, Content: content})
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack layer %s: %w", digest, err)
+		}
+
+		return files, nil
+	default:
+		// other layers, like Helm provenance files, can't contain CRDs.
+		return nil, nil
+	}
+}
@@ -0,0 +1,205 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: bundles.test.example.com
+spec:
+  group: test.example.com
+  names:
+    kind: Bundle
+    plural: bundles
+  scope: Namespaced
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          type: object
+`
+
+func tarGz(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	return buf.Bytes()
+}
+
+// chartArchive packages the test chart of the helm package.
+func chartArchive(t *testing.T) []byte {
+	t.Helper()
+
+	root := filepath.Join("..", "helm", "testdata", "chart")
+	files := map[string][]byte{}
+
+	require.NoError(t, filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		files["test-operator/"+filepath.ToSlash(rel)] = content
+
+		return nil
+	}))
+
+	return tarGz(t, files)
+}
+
+func artifact(t *testing.T) v1.Image {
+	t.Helper()
+
+	img, err := mutate.Append(empty.Image,
+		mutate.Addendum{Layer: static.NewLayer(tarGz(t, map[string][]byte{
+			"crds/bundle.yaml": []byte(crd),
+			"README.md":        []byte("# readme"),
+		}), types.OCILayer)},
+		mutate.Addendum{Layer: static.NewLayer(chartArchive(t), HelmChartMediaType)},
+		mutate.Addendum{
+			Layer:       static.NewLayer([]byte(crd), "application/yaml"),
+			Annotations: map[string]string{titleAnnotation: "single.yaml"},
+		},
+		mutate.Addendum{Layer: static.NewLayer([]byte("signature"), "application/vnd.cncf.helm.chart.provenance.v1.prov")},
+	)
+	require.NoError(t, err)
+
+	return mutate.MediaType(img, types.OCIManifestSchema1)
+}
+
+func names(files []File) []string {
+	result := make([]string, 0, len(files))
+	for _, f := range files {
+		result = append(result, f.Name)
+	}
+
+	return result
+}
+
+func assertArtifactFiles(t *testing.T, files []File) {
+	t.Helper()
+
+	assert.Equal(t, []string{
+		"crds/bundle.yaml",
+		"test-operator/crds/static.yaml",
+		"test-operator/templates/configmap.yaml",
+		"test-operator/templates/crd.yaml",
+		"single.yaml",
+	}, names(files))
+	assert.Equal(t, crd, string(files[0].Content))
+	assert.True(t, strings.HasPrefix(files[0].Layer, "sha256:"))
+	assert.False(t, files[0].Rendered)
+	assert.True(t, files[3].Rendered)
+	assert.Contains(t, string(files[3].Content), "- Fast\n")
+}
+
+func TestPullFromRegistry(t *testing.T) {
+	const username, password = "user", "secret"
+
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	ref := host + "/crds:v1.0.0"
+
+	reference, err := name.ParseReference(ref)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(reference, artifact(t), remote.WithAuth(&authn.Basic{Username: username, Password: password})))
+
+	_, err = Pull(ref)
+	require.Error(t, err, "pulling without credentials must fail")
+
+	dockerConfig := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	require.NoError(t, os.WriteFile(filepath.Join(dockerConfig, "config.json"),
+		[]byte(`{"auths": {"`+host+`": {"auth": "`+auth+`"}}}`), 0o600))
+	t.Setenv("DOCKER_CONFIG", dockerConfig)
+
+	files, err := Pull(ref)
+	require.NoError(t, err)
+	assertArtifactFiles(t, files)
+}
+
+func TestPullFromLayout(t *testing.T) {
+	dir := t.TempDir()
+
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendImage(artifact(t), layout.WithAnnotations(map[string]string{refNameAnnotation: "v1.0.0"})))
+
+	files, err := Pull(dir)
+	require.NoError(t, err)
+	assertArtifactFiles(t, files)
+
+	require.NoError(t, p.AppendImage(empty.Image, layout.WithAnnotations(map[string]string{refNameAnnotation: "empty"})))
+
+	_, err = Pull(dir)
+	require.ErrorContains(t, err, "contains 2 manifests")
+
+	files, err = Pull(dir + ":v1.0.0")
+	require.NoError(t, err)
+	assertArtifactFiles(t, files)
+
+	_, err = Pull(dir + ":v2.0.0")
+	require.ErrorContains(t, err, "no manifest with ref name v2.0.0")
+}
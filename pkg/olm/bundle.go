@@ -0,0 +1,213 @@
+// Package olm loads Operator Lifecycle Manager bundles and checks, or regenerates, the examples the
+// ClusterServiceVersion of a bundle contains in its alm-examples annotation.
+package olm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/filter"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/matches"
+)
+
+const (
+	// ExamplesAnnotation is the annotation of the ClusterServiceVersion that holds a JSON list of example resources.
+	ExamplesAnnotation = "alm-examples"
+
+	manifestsFolder   = "manifests"
+	crdKind           = "CustomResourceDefinition"
+	csvKind           = "ClusterServiceVersion"
+	decoderBufferSize = 4096
+)
+
+// Bundle is the content of the manifests folder of a bundle.
+type Bundle struct {
+	// CSVPath is the file that contains the ClusterServiceVersion.
+	CSVPath string
+	// CRDs are the CustomResourceDefinitions of the bundle as JSON.
+	CRDs []CRD
+	// Owned are the CRD versions the ClusterServiceVersion declares as owned by the operator.
+	Owned []OwnedCRD
+	// Examples are the resources of the alm-examples annotation.
+	Examples []map[string]any
+}
+
+// CRD is a CustomResourceDefinition of the bundle.
+type CRD struct {
+	Path    string
+	Group   string
+	Kind    string
+	Content []byte
+}
+
+// OwnedCRD is an entry of spec.customresourcedefinitions.owned of the ClusterServiceVersion.
+type OwnedCRD struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// Load reads the bundle in dir. The folder is either the bundle root, containing a manifests folder, or the
+// manifests folder itself. The bundle must contain exactly one ClusterServiceVersion.
+func Load(dir string) (*Bundle, error) {
+	manifests := filepath.Join(dir, manifestsFolder)
+	if _, err := os.Stat(manifests); err != nil {
+		manifests = dir
+	}
+
+	entries, err := os.ReadDir(manifests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle manifests in %s: %w", manifests, err)
+	}
+
+	bundle := &Bundle{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !filter.HasExtension(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(manifests, entry.Name())
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if err := bundle.add(path, content); err != nil {
+			return nil, err
+		}
+	}
+
+	if bundle.CSVPath == "" {
+		return nil, fmt.Errorf("no %s found in %s", csvKind, manifests)
+	}
+
+	return bundle, nil
+}
+
+// add decodes the documents of a manifest and keeps the CRDs and the ClusterServiceVersion.
+func (b *Bundle) add(path string, content []byte) error {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), decoderBufferSize)
+
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+
+		switch obj.GetKind() {
+		case crdKind:
+			if err := b.addCRD(path, obj); err != nil {
+				return err
+			}
+		case csvKind:
+			if b.CSVPath != "" {
+				return fmt.Errorf("bundle contains more than one %s: %s and %s", csvKind, b.CSVPath, path)
+			}
+
+			if err := b.addCSV(path, obj); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (b *Bundle) addCRD(path string, obj *unstructured.Unstructured) error {
+	group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+
+	content, err := obj.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal CRD in %s: %w", path, err)
+	}
+
+	b.CRDs = append(b.CRDs, CRD{Path: path, Group: group, Kind: kind, Content: content})
+
+	return nil
+}
+
+func (b *Bundle) addCSV(path string, obj *unstructured.Unstructured) error {
+	b.CSVPath = path
+
+	owned, _, err := unstructured.NestedSlice(obj.Object, "spec", "customresourcedefinitions", "owned")
+	if err != nil {
+		return fmt.Errorf("invalid owned CRDs in %s: %w", path, err)
+	}
+
+	content, err := json.Marshal(owned)
+	if err != nil {
+		return fmt.Errorf("failed to marshal owned CRDs of %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(content, &b.Owned); err != nil {
+		return fmt.Errorf("invalid owned CRDs in %s: %w", path, err)
+	}
+
+	examples := obj.GetAnnotations()[ExamplesAnnotation]
+	if examples == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(examples), &b.Examples); err != nil {
+		return fmt.Errorf("failed to parse %s annotation of %s: %w", ExamplesAnnotation, path, err)
+	}
+
+	return nil
+}
+
+// CRDContents returns the CRDs of the bundle as a single multi document stream.
+func (b *Bundle) CRDContents() []byte {
+	var result []byte
+
+	for _, crd := range b.CRDs {
+		result = append(result, []byte("---\n")...)
+		result = append(result, crd.Content...)
+		result = append(result, '\n')
+	}
+
+	return result
+}
+
+// Validate validates every example against the CRD of its kind. All failures are returned.
+func (b *Bundle) Validate() error {
+	var errs []error
+
+	for i, example := range b.Examples {
+		obj := &unstructured.Unstructured{Object: example}
+		gvk := obj.GroupVersionKind()
+
+		index := slices.IndexFunc(b.CRDs, func(crd CRD) bool {
+			return crd.Group == gvk.Group && crd.Kind == gvk.Kind
+		})
+		if index < 0 {
+			errs = append(errs, fmt.Errorf("%s[%d]: no CRD found in the bundle for %s", ExamplesAnnotation, i, gvk))
+
+			continue
+		}
+
+		content, err := json.Marshal(example)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s[%d]: %w", ExamplesAnnotation, i, err)
+		}
+
+		if err := matches.Validate(b.CRDs[index].Content, content, nil); err != nil {
+			errs = append(errs, fmt.Errorf("%s[%d]: %w", ExamplesAnnotation, i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package olm
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	for _, dir := range []string{"bundle", filepath.Join("bundle", "manifests")} {
+		t.Run(dir, func(t *testing.T) {
+			bundle, err := Load(filepath.Join("testdata", dir))
+			require.NoError(t, err)
+
+			assert.Equal(t, filepath.Join("testdata", "bundle", "manifests", "memcached-operator.clusterserviceversion.yaml"), bundle.CSVPath)
+			require.Len(t, bundle.CRDs, 1)
+			assert.Equal(t, "cache.example.com", bundle.CRDs[0].Group)
+			assert.Equal(t, "Memcached", bundle.CRDs[0].Kind)
+			assert.Equal(t, []OwnedCRD{{Name: "memcacheds.cache.example.com", Version: "v1alpha1", Kind: "Memcached"}}, bundle.Owned)
+			assert.Len(t, bundle.Examples, 3)
+		})
+	}
+}
+
+func TestLoadWithoutCSV(t *testing.T) {
+	_, err := Load(t.TempDir())
+	require.ErrorContains(t, err, "no ClusterServiceVersion found")
+}
+
+func TestValidate(t *testing.T) {
+	bundle, err := Load(filepath.Join("testdata", "bundle"))
+	require.NoError(t, err)
+
+	err = bundle.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "alm-examples[0]: ")
+	assert.Contains(t, err.Error(), "spec.size")
+	assert.NotContains(t, err.Error(), "alm-examples[1]")
+	assert.Contains(t, err.Error(), "alm-examples[2]: no CRD found in the bundle for cache.example.com/v1alpha1, Kind=Unknown")
+}
@@ -0,0 +1,177 @@
+package olm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+const yamlIndent = 2
+
+// GenerateExamples generates an example for every version of the schema types the bundle owns. If the
+// ClusterServiceVersion doesn't list owned CRDs, every version is used. Examples are named
+// `<kind>-sample` and don't contain random values so regenerating them is stable.
+func (b *Bundle) GenerateExamples(schemaTypes []*pkg.SchemaType, minimal bool) ([]map[string]any, error) {
+	var result []map[string]any
+
+	for _, schemaType := range schemaTypes {
+		parser := pkg.NewParser(schemaType.Group, schemaType.Kind, false, minimal, true)
+
+		for _, version := range schemaType.Versions {
+			if !b.owns(schemaType, version.Name) {
+				continue
+			}
+
+			buffer := &bytes.Buffer{}
+			if err := parser.ParseProperties(version.Name, buffer, version.Schema.Properties, pkg.RootRequiredFields); err != nil {
+				return nil, fmt.Errorf("failed to generate example for %s %s: %w", schemaType.Kind, version.Name, err)
+			}
+
+			content, err := k8syaml.ToJSON(buffer.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert example for %s %s: %w", schemaType.Kind, version.Name, err)
+			}
+
+			example := map[string]any{}
+			if err := json.Unmarshal(content, &example); err != nil {
+				return nil, fmt.Errorf("failed to convert example for %s %s: %w", schemaType.Kind, version.Name, err)
+			}
+
+			setName(example, strings.ToLower(schemaType.Kind)+"-sample")
+
+			result = append(result, example)
+		}
+	}
+
+	return result, nil
+}
+
+func (b *Bundle) owns(schemaType *pkg.SchemaType, version string) bool {
+	if len(b.Owned) == 0 {
+		return true
+	}
+
+	for _, owned := range b.Owned {
+		if owned.Kind == schemaType.Kind && owned.Version == version && strings.HasSuffix(owned.Name, "."+schemaType.Group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func setName(example map[string]any, name string) {
+	metadata, ok := example["metadata"].(map[string]any)
+	if !ok {
+		metadata = map[string]any{}
+		example["metadata"] = metadata
+	}
+
+	if _, ok := metadata["name"]; !ok {
+		metadata["name"] = name
+	}
+}
+
+// SetExamples replaces the alm-examples annotation of the ClusterServiceVersion in content. Content
+// may contain other documents, which are kept as they are. The rest of the document, including
+// comments and the order of the fields, is kept.
+func SetExamples(content []byte, examples []map[string]any) ([]byte, error) {
+	var docs []*yaml.Node
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		doc := &yaml.Node{}
+		if err := decoder.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("failed to parse ClusterServiceVersion: %w", err)
+		}
+
+		docs = append(docs, doc)
+	}
+
+	csv := findCSV(docs)
+	if csv == nil {
+		return nil, errors.New("no ClusterServiceVersion object found")
+	}
+
+	if examples == nil {
+		examples = []map[string]any{}
+	}
+
+	value, err := json.MarshalIndent(examples, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal examples: %w", err)
+	}
+
+	metadata := mappingValue(csv, "metadata")
+	annotations := mappingValue(metadata, "annotations")
+	annotation := mappingValue(annotations, ExamplesAnnotation)
+	annotation.Kind = yaml.ScalarNode
+	annotation.Tag = "!!str"
+	annotation.Style = yaml.LiteralStyle
+	annotation.Value = string(value)
+	annotation.Content = nil
+
+	result := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(result)
+	encoder.SetIndent(yamlIndent)
+
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, fmt.Errorf("failed to encode ClusterServiceVersion: %w", err)
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode ClusterServiceVersion: %w", err)
+	}
+
+	return result.Bytes(), nil
+}
+
+// findCSV returns the object of the document whose kind is ClusterServiceVersion.
+func findCSV(docs []*yaml.Node) *yaml.Node {
+	for _, doc := range docs {
+		if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		obj := doc.Content[0]
+		for i := 0; i+1 < len(obj.Content); i += 2 {
+			if obj.Content[i].Value == "kind" && obj.Content[i+1].Value == csvKind {
+				return obj
+			}
+		}
+	}
+
+	return nil
+}
+
+// mappingValue returns the value of key in the mapping and adds an empty mapping if the key doesn't exist.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	// JSON documents are decoded in flow style which would put the whole annotation on a single line.
+	mapping.Style = 0
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+
+	return value
+}
@@ -0,0 +1,70 @@
+package olm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestExamplesRoundTrip(t *testing.T) {
+	bundle, err := Load(filepath.Join("testdata", "bundle"))
+	require.NoError(t, err)
+
+	schemaTypes, err := pkg.DecodeRenderedSchemaTypes(bundle.CRDContents(), "bundle", os.Stderr)
+	require.NoError(t, err)
+
+	examples, err := bundle.GenerateExamples(schemaTypes, true)
+	require.NoError(t, err)
+	require.Len(t, examples, 1)
+	assert.Equal(t, map[string]any{
+		"apiVersion": "cache.example.com/v1alpha1",
+		"kind":       "Memcached",
+		"metadata":   map[string]any{"name": "memcached-sample"},
+		"spec":       map[string]any{"size": float64(1)},
+		"status":     map[string]any{},
+	}, examples[0])
+
+	content, err := os.ReadFile(bundle.CSVPath)
+	require.NoError(t, err)
+
+	updated, err := SetExamples(content, examples)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(updated), "    # examples shown in the OperatorHub UI.\n    alm-examples: |-\n      [\n        {\n")
+	assert.Contains(t, string(updated), "    capabilities: Basic Install\n")
+	assert.Contains(t, string(updated), "  displayName: Memcached Operator\n")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "csv.yaml"), updated, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crd.yaml"), bundle.CRDs[0].Content, 0o600))
+
+	regenerated, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, examples, regenerated.Examples)
+	require.NoError(t, regenerated.Validate())
+}
+
+func TestSetExamplesWithoutAnnotations(t *testing.T) {
+	content := "apiVersion: operators.coreos.com/v1alpha1\nkind: ClusterServiceVersion\nmetadata:\n  name: test\n"
+
+	updated, err := SetExamples([]byte(content), nil)
+	require.NoError(t, err)
+	assert.Equal(t, content+"  annotations:\n    alm-examples: |-\n      []\n", string(updated))
+}
+
+func TestSetExamplesInMultiDocumentManifest(t *testing.T) {
+	crd := "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: memcacheds.cache.example.com\n"
+	csv := "apiVersion: operators.coreos.com/v1alpha1\nkind: ClusterServiceVersion\nmetadata:\n  name: test\n"
+
+	updated, err := SetExamples([]byte(crd+"---\n"+csv), nil)
+	require.NoError(t, err)
+	assert.Equal(t, crd+"---\n"+csv+"  annotations:\n    alm-examples: |-\n      []\n", string(updated))
+
+	_, err = SetExamples([]byte(crd), nil)
+	require.ErrorContains(t, err, "no ClusterServiceVersion object found")
+}
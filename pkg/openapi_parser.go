@@ -0,0 +1,257 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+const (
+	componentRefPrefix = "#/components/schemas/"
+	gvkExtension       = "x-kubernetes-group-version-kind"
+)
+
+// openAPIDocument is the part of an OpenAPI v3 document that contains the schemas.
+type openAPIDocument struct {
+	Components struct {
+		Schemas map[string]any `json:"schemas"`
+	} `json:"components"`
+}
+
+// openAPIResolver inlines references to component schemas.
+type openAPIResolver struct {
+	schemas  map[string]any
+	resolved map[string]any
+	// cycles counts the references that couldn't be inlined because they refer to themselves.
+	cycles int
+}
+
+// ParseOpenAPIV3 turns every component of an OpenAPI v3 document, like the ones served by the
+// Kubernetes API server under `/openapi/v3/apis/<group>/<version>`, into a schema type if it has
+// a single `x-kubernetes-group-version-kind`. Components shared by many groups, like DeleteOptions,
+// and lists are skipped. References are inlined. Recursive references allow any content.
+func ParseOpenAPIV3(content []byte) ([]*SchemaType, error) {
+	doc := &openAPIDocument{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal OpenAPI document: %w", err)
+	}
+
+	if len(doc.Components.Schemas) == 0 {
+		return nil, errors.New("no component schemas found in OpenAPI document")
+	}
+
+	r := &openAPIResolver{schemas: doc.Components.Schemas, resolved: map[string]any{}}
+	kinds := map[string]*SchemaType{}
+
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		component, ok := doc.Components.Schemas[name].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		group, version, kind, ok := groupVersionKind(component)
+		if !ok || isList(kind, component) {
+			continue
+		}
+
+		resolved, err := r.resolveRef(name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", name, err)
+		}
+
+		schema, err := toJSONSchemaProps(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", name, err)
+		}
+
+		if schema.Properties == nil {
+			schema.Properties = map[string]v1beta1.JSONSchemaProps{}
+		}
+
+		ensureKindAndAPIVersionIsSet(schema.Properties)
+
+		key := group + "/" + kind
+
+		schemaType, ok := kinds[key]
+		if !ok {
+			schemaType = &SchemaType{Group: group, Kind: kind}
+			kinds[key] = schemaType
+		}
+
+		schemaType.Versions = append(schemaType.Versions, &CRDVersion{Name: version, Schema: schema})
+	}
+
+	result := slices.Collect(maps.Values(kinds))
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Group != result[j].Group {
+			return result[i].Group < result[j].Group
+		}
+
+		return result[i].Kind < result[j].Kind
+	})
+
+	return result, nil
+}
+
+// resolveRef returns the component with every reference inlined. The stack contains the components that
+// are being resolved to detect recursion.
+func (r *openAPIResolver) resolveRef(name string, stack []string) (any, error) {
+	if resolved, ok := r.resolved[name]; ok {
+		return resolved, nil
+	}
+
+	if slices.Contains(stack, name) {
+		r.cycles++
+
+		return map[string]any{"type": typeObject, "x-kubernetes-preserve-unknown-fields": true}, nil
+	}
+
+	component, ok := r.schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("reference to unknown component %s", name)
+	}
+
+	cycles := r.cycles
+
+	resolved, err := r.resolve(component, append(stack, name))
+	if err != nil {
+		return nil, err
+	}
+
+	// components that contain a cut off recursion depend on where they are used.
+	if r.cycles == cycles {
+		r.resolved[name] = resolved
+	}
+
+	return resolved, nil
+}
+
+func (r *openAPIResolver) resolve(node any, stack []string) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		if ref, ok := n["$ref"].(string); ok {
+			return r.resolveWithSiblings(ref, n, "$ref", stack)
+		}
+
+		// Kubernetes wraps references in a single allOf so siblings like description and default are allowed.
+		if allOf, ok := n["allOf"].([]any); ok && len(allOf) == 1 {
+			if inner, ok := allOf[0].(map[string]any); ok {
+				if ref, ok := inner["$ref"].(string); ok {
+					return r.resolveWithSiblings(ref, n, "allOf", stack)
+				}
+			}
+		}
+
+		result := make(map[string]any, len(n))
+
+		for k, v := range n {
+			resolved, err := r.resolve(v, stack)
+			if err != nil {
+				return nil, err
+			}
+
+			result[k] = resolved
+		}
+
+		return result, nil
+	case []any:
+		result := make([]any, 0, len(n))
+
+		for _, v := range n {
+			resolved, err := r.resolve(v, stack)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, resolved)
+		}
+
+		return result, nil
+	default:
+		return node, nil
+	}
+}
+
+// resolveWithSiblings inlines the reference and lets the other keys of the node, except the one
+// holding the reference, override the referenced schema.
+func (r *openAPIResolver) resolveWithSiblings(ref string, node map[string]any, refKey string, stack []string) (any, error) {
+	name, ok := strings.CutPrefix(ref, componentRefPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unsupported reference %s, only %s references are supported", ref, componentRefPrefix)
+	}
+
+	name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+
+	resolved, err := r.resolveRef(name, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{}
+	if m, ok := resolved.(map[string]any); ok {
+		maps.Copy(result, m)
+	}
+
+	for k, v := range node {
+		if k == refKey {
+			continue
+		}
+
+		sibling, err := r.resolve(v, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		result[k] = sibling
+	}
+
+	return result, nil
+}
+
+// groupVersionKind returns the single group, version and kind of a component.
+func groupVersionKind(component map[string]any) (string, string, string, bool) {
+	gvks, ok := component[gvkExtension].([]any)
+	if !ok || len(gvks) != 1 {
+		return "", "", "", false
+	}
+
+	gvk, ok := gvks[0].(map[string]any)
+	if !ok {
+		return "", "", "", false
+	}
+
+	group, _ := gvk["group"].(string)
+	version, _ := gvk["version"].(string)
+	kind, _ := gvk["kind"].(string)
+
+	return group, version, kind, version != "" && kind != ""
+}
+
+func isList(kind string, component map[string]any) bool {
+	properties, _ := component["properties"].(map[string]any)
+	_, hasItems := properties["items"]
+
+	return strings.HasSuffix(kind, "List") && hasItems
+}
+
+func toJSONSchemaProps(schema any) (*v1beta1.JSONSchemaProps, error) {
+	content, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	props := &v1beta1.JSONSchemaProps{}
+	if err := json.Unmarshal(content, props); err != nil {
+		return nil, err
+	}
+
+	return props, nil
+}
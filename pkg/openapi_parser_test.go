@@ -0,0 +1,73 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpenAPIV3(t *testing.T) {
+	content, err := os.ReadFile("testdata/openapi_apps_v1.json")
+	require.NoError(t, err)
+
+	schemaTypes, err := ParseOpenAPIV3(content)
+	require.NoError(t, err)
+	require.Len(t, schemaTypes, 2)
+
+	configMap, deployment := schemaTypes[0], schemaTypes[1]
+	assert.Empty(t, configMap.Group)
+	assert.Equal(t, "ConfigMap", configMap.Kind)
+	assert.Equal(t, "apps", deployment.Group)
+	assert.Equal(t, "Deployment", deployment.Kind)
+	require.Len(t, deployment.Versions, 1)
+	assert.Equal(t, "v1", deployment.Versions[0].Name)
+
+	schema := deployment.Versions[0].Schema
+	spec := schema.Properties["spec"]
+	assert.Equal(t, "Specification of the desired behavior of the Deployment.", spec.Description)
+	assert.Equal(t, []string{"selector"}, spec.Required)
+
+	selector := spec.Properties["selector"]
+	assert.Equal(t, "Label selector for pods.", selector.Description)
+	assert.Equal(t, []string{"key", "operator"}, selector.Properties["matchExpressions"].Items.Schema.Required)
+
+	owner := schema.Properties["metadata"].Properties["ownerReferences"].Items.Schema.Properties["owner"]
+	require.NotNil(t, owner.XPreserveUnknownFields)
+	assert.True(t, *owner.XPreserveUnknownFields)
+	assert.Empty(t, owner.Properties)
+}
+
+func TestParseOpenAPIV3GenerateSample(t *testing.T) {
+	content, err := os.ReadFile("testdata/openapi_apps_v1.json")
+	require.NoError(t, err)
+
+	schemaTypes, err := ParseOpenAPIV3(content)
+	require.NoError(t, err)
+
+	var output bytes.Buffer
+
+	for _, schemaType := range schemaTypes {
+		version := schemaType.Versions[0]
+		parser := NewParser(schemaType.Group, schemaType.Kind, false, false, true)
+		require.NoError(t, parser.ParseProperties(version.Name, &output, version.Schema.Properties, RootRequiredFields))
+	}
+
+	golden, err := os.ReadFile("testdata/openapi_apps_v1_golden.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), output.String())
+}
+
+func TestParseOpenAPIV3Errors(t *testing.T) {
+	_, err := ParseOpenAPIV3([]byte(`{"openapi": "3.0.0"}`))
+	require.ErrorContains(t, err, "no component schemas found")
+
+	_, err = ParseOpenAPIV3([]byte(`{"components": {"schemas": {"a.Thing": {
+		"type": "object",
+		"properties": {"spec": {"$ref": "#/components/schemas/a.Missing"}},
+		"x-kubernetes-group-version-kind": [{"group": "a", "kind": "Thing", "version": "v1"}]
+	}}}}`))
+	require.ErrorContains(t, err, "reference to unknown component a.Missing")
+}
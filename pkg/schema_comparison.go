@@ -0,0 +1,255 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComparisonReport combines the validation reports of two sets of CRDs, like the CRDs of a repository
+// at two git refs.
+type ComparisonReport struct {
+	Base    string        `json:"base"    yaml:"base"`
+	Head    string        `json:"head"    yaml:"head"`
+	Kinds   []*KindReport `json:"kinds"   yaml:"kinds"`
+	Summary Summary       `json:"summary" yaml:"summary"`
+}
+
+// KindReport contains the changes of a single group and kind. Changes lists added and removed
+// kinds and versions, Versions the validation report of every version that exists in both.
+type KindReport struct {
+	Group    string              `json:"group"              yaml:"group"`
+	Kind     string              `json:"kind"               yaml:"kind"`
+	Changes  []Change            `json:"changes,omitempty"  yaml:"changes,omitempty"`
+	Versions []*ValidationReport `json:"versions,omitempty" yaml:"versions,omitempty"`
+}
+
+// HasBreakingChanges returns true if the report contains any breaking changes.
+func (r *ComparisonReport) HasBreakingChanges() bool {
+	return r.Summary.BreakingChanges > 0
+}
+
+// OutputJSON writes the comparison report as JSON.
+func (r *ComparisonReport) OutputJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+// OutputYAML writes the comparison report as YAML.
+func (r *ComparisonReport) OutputYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+
+	defer func() {
+		_ = encoder.Close()
+	}()
+
+	return encoder.Encode(r)
+}
+
+// OutputText writes the comparison report as human-readable text.
+func (r *ComparisonReport) OutputText(w io.Writer) error {
+	wr := &writer{}
+	wr.write(w, "Schema Comparison Report\n")
+	wr.write(w, "========================\n\n")
+	wr.write(w, fmt.Sprintf("Base: %s\n", r.Base))
+	wr.write(w, fmt.Sprintf("Head: %s\n\n", r.Head))
+
+	wr.write(w, "Summary:\n")
+	wr.write(w, fmt.Sprintf("  Total Changes: %d\n", r.Summary.TotalChanges))
+	wr.write(w, fmt.Sprintf("  Breaking Changes: %d\n", r.Summary.BreakingChanges))
+	wr.write(w, fmt.Sprintf("  Additions: %d\n", r.Summary.Additions))
+	wr.write(w, fmt.Sprintf("  Removals: %d\n", r.Summary.Removals))
+
+	for _, kind := range r.Kinds {
+		wr.write(w, fmt.Sprintf("\n%s/%s:\n", kind.Group, kind.Kind))
+
+		writeChanges(wr, w, "  ", kind.Changes)
+
+		for _, version := range kind.Versions {
+			wr.write(w, fmt.Sprintf("  %s:\n", version.ToVersion))
+
+			if len(version.Changes) == 0 {
+				wr.write(w, "    No changes detected.\n")
+
+				continue
+			}
+
+			writeChanges(wr, w, "    ", version.Changes)
+		}
+	}
+
+	if wr.err != nil {
+		return fmt.Errorf("failed to write report: %w", wr.err)
+	}
+
+	return nil
+}
+
+func writeChanges(wr *writer, w io.Writer, indent string, changes []Change) {
+	for _, change := range changes {
+		symbol := getChangeSymbol(change.Type)
+		wr.write(w, fmt.Sprintf("%s%s [%s] %s: %s\n", indent, symbol, change.Type, change.Path, change.Description))
+
+		if change.OldValue != "" {
+			wr.write(w, fmt.Sprintf("%s  Old: %s\n", indent, change.OldValue))
+		}
+
+		if change.NewValue != "" {
+			wr.write(w, fmt.Sprintf("%s  New: %s\n", indent, change.NewValue))
+		}
+	}
+}
+
+// CompareSchemaTypes matches the CRDs of base and head by group and kind and validates every version
+// that exists in both. Removed kinds and versions are breaking changes, added ones are additions.
+// If a group and kind is found more than once, the first one is used.
+func (v *SchemaValidator) CompareSchemaTypes(baseName string, base []*SchemaType, headName string, head []*SchemaType) (*ComparisonReport, error) {
+	baseKinds := indexSchemaTypes(base)
+	headKinds := indexSchemaTypes(head)
+
+	keys := make([]string, 0, len(baseKinds)+len(headKinds))
+	for key := range baseKinds {
+		keys = append(keys, key)
+	}
+
+	for key := range headKinds {
+		if _, ok := baseKinds[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	report := &ComparisonReport{
+		Base: baseName,
+		Head: headName,
+	}
+
+	var changes []Change
+
+	for _, key := range keys {
+		from, to := baseKinds[key], headKinds[key]
+
+		var kind *KindReport
+
+		switch {
+		case to == nil:
+			kind = &KindReport{Group: from.Group, Kind: from.Kind, Changes: []Change{{
+				Type:        BreakingChange,
+				Path:        "kind",
+				Description: fmt.Sprintf("Kind '%s' removed", from.Kind),
+				OldValue:    strings.Join(versionNames(from), ", "),
+			}}}
+		case from == nil:
+			kind = &KindReport{Group: to.Group, Kind: to.Kind, Changes: []Change{{
+				Type:        Addition,
+				Path:        "kind",
+				Description: fmt.Sprintf("Kind '%s' added", to.Kind),
+				NewValue:    strings.Join(versionNames(to), ", "),
+			}}}
+		default:
+			var err error
+
+			kind, err = v.compareKind(from, to)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		changes = append(changes, kind.Changes...)
+		for _, version := range kind.Versions {
+			changes = append(changes, version.Changes...)
+		}
+
+		report.Kinds = append(report.Kinds, kind)
+	}
+
+	report.Summary = v.calculateSummary(changes)
+
+	return report, nil
+}
+
+func (v *SchemaValidator) compareKind(from, to *SchemaType) (*KindReport, error) {
+	kind := &KindReport{Group: to.Group, Kind: to.Kind}
+
+	fromVersions, toVersions := versionNames(from), versionNames(to)
+
+	for _, version := range fromVersions {
+		if !slices.Contains(toVersions, version) {
+			kind.Changes = append(kind.Changes, Change{
+				Type:        BreakingChange,
+				Path:        "versions",
+				Description: fmt.Sprintf("Version '%s' removed", version),
+				OldValue:    version,
+			})
+
+			continue
+		}
+
+		fromSchema, err := v.findVersionSchema(from, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find version %s of %s: %w", version, from.Kind, err)
+		}
+
+		toSchema, err := v.findVersionSchema(to, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find version %s of %s: %w", version, to.Kind, err)
+		}
+
+		changes := v.compareSchemas("spec", fromSchema, toSchema)
+		kind.Versions = append(kind.Versions, &ValidationReport{
+			CRDKind:     to.Kind,
+			FromVersion: version,
+			ToVersion:   version,
+			Changes:     changes,
+			Summary:     v.calculateSummary(changes),
+		})
+	}
+
+	for _, version := range toVersions {
+		if !slices.Contains(fromVersions, version) {
+			kind.Changes = append(kind.Changes, Change{
+				Type:        Addition,
+				Path:        "versions",
+				Description: fmt.Sprintf("Version '%s' added", version),
+				NewValue:    version,
+			})
+		}
+	}
+
+	return kind, nil
+}
+
+func indexSchemaTypes(schemaTypes []*SchemaType) map[string]*SchemaType {
+	index := make(map[string]*SchemaType, len(schemaTypes))
+
+	for _, schemaType := range schemaTypes {
+		key := schemaType.Group + "/" + schemaType.Kind
+		if _, ok := index[key]; !ok {
+			index[key] = schemaType
+		}
+	}
+
+	return index
+}
+
+// versionNames returns the names of the versions of the schema type, including the version of a
+// top level validation.
+func versionNames(schemaType *SchemaType) []string {
+	names := make([]string, 0, len(schemaType.Versions)+1)
+	for _, version := range schemaType.Versions {
+		names = append(names, version.Name)
+	}
+
+	if schemaType.Validation != nil && !slices.Contains(names, schemaType.Validation.Name) {
+		names = append(names, schemaType.Validation.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+func comparisonSchema(required ...string) *v1beta1.JSONSchemaProps {
+	return &v1beta1.JSONSchemaProps{
+		Type:     "object",
+		Required: required,
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"name":  {Type: "string"},
+			"count": {Type: "integer"},
+		},
+	}
+}
+
+func TestCompareSchemaTypes(t *testing.T) {
+	base := []*SchemaType{
+		{Group: "example.com", Kind: "Foo", Versions: []*CRDVersion{
+			{Name: "v1alpha1", Schema: comparisonSchema()},
+			{Name: "v1beta1", Schema: comparisonSchema()},
+		}},
+		{Group: "example.com", Kind: "Removed", Versions: []*CRDVersion{{Name: "v1", Schema: comparisonSchema()}}},
+		{Group: "other.com", Kind: "Foo", Validation: &Validation{Name: "v1", Schema: comparisonSchema()}},
+	}
+	head := []*SchemaType{
+		{Group: "other.com", Kind: "Foo", Validation: &Validation{Name: "v1", Schema: comparisonSchema()}},
+		{Group: "example.com", Kind: "Foo", Versions: []*CRDVersion{
+			{Name: "v1beta1", Schema: comparisonSchema("name")},
+			{Name: "v1", Schema: comparisonSchema()},
+		}},
+		{Group: "example.com", Kind: "Added", Versions: []*CRDVersion{{Name: "v1", Schema: comparisonSchema()}}},
+	}
+
+	report, err := NewSchemaValidator().CompareSchemaTypes("v1.0.0", base, "main", head)
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.0.0", report.Base)
+	assert.Equal(t, "main", report.Head)
+	assert.True(t, report.HasBreakingChanges())
+	assert.Equal(t, Summary{TotalChanges: 5, BreakingChanges: 3, Additions: 2}, report.Summary)
+
+	require.Len(t, report.Kinds, 4)
+
+	added := report.Kinds[0]
+	assert.Equal(t, "Added", added.Kind)
+	assert.Equal(t, []Change{{Type: Addition, Path: "kind", Description: "Kind 'Added' added", NewValue: "v1"}}, added.Changes)
+
+	foo := report.Kinds[1]
+	assert.Equal(t, "example.com", foo.Group)
+	assert.Equal(t, "Foo", foo.Kind)
+	assert.Equal(t, []Change{
+		{Type: BreakingChange, Path: "versions", Description: "Version 'v1alpha1' removed", OldValue: "v1alpha1"},
+		{Type: Addition, Path: "versions", Description: "Version 'v1' added", NewValue: "v1"},
+	}, foo.Changes)
+	require.Len(t, foo.Versions, 1)
+	assert.Equal(t, "v1beta1", foo.Versions[0].ToVersion)
+	assert.Equal(t, []Change{{
+		Type:        BreakingChange,
+		Path:        "spec.required",
+		Description: "Field 'name' is now required",
+		NewValue:    "name",
+	}}, foo.Versions[0].Changes)
+
+	removed := report.Kinds[2]
+	assert.Equal(t, "Removed", removed.Kind)
+	assert.Equal(t, []Change{{Type: BreakingChange, Path: "kind", Description: "Kind 'Removed' removed", OldValue: "v1"}}, removed.Changes)
+
+	other := report.Kinds[3]
+	assert.Equal(t, "other.com", other.Group)
+	assert.Empty(t, other.Changes)
+	require.Len(t, other.Versions, 1)
+	assert.Empty(t, other.Versions[0].Changes)
+}
+
+func TestCompareSchemaTypesWithoutChanges(t *testing.T) {
+	crds := []*SchemaType{
+		{Group: "example.com", Kind: "Foo", Versions: []*CRDVersion{{Name: "v1", Schema: comparisonSchema()}}},
+	}
+
+	report, err := NewSchemaValidator().CompareSchemaTypes("v1.0.0", crds, "main", crds)
+	require.NoError(t, err)
+	assert.False(t, report.HasBreakingChanges())
+	assert.Equal(t, Summary{}, report.Summary)
+}
+
+func TestComparisonReport_OutputText(t *testing.T) {
+	base := []*SchemaType{
+		{Group: "example.com", Kind: "Foo", Versions: []*CRDVersion{
+			{Name: "v1alpha1", Schema: comparisonSchema()},
+			{Name: "v1", Schema: comparisonSchema()},
+		}},
+	}
+	head := []*SchemaType{
+		{Group: "example.com", Kind: "Foo", Versions: []*CRDVersion{{Name: "v1", Schema: comparisonSchema()}}},
+	}
+
+	report, err := NewSchemaValidator().CompareSchemaTypes("v1.0.0", base, "main", head)
+	require.NoError(t, err)
+
+	var output strings.Builder
+	require.NoError(t, report.OutputText(&output))
+	assert.Equal(t, `Schema Comparison Report
+========================
+
+Base: v1.0.0
+Head: main
+
+Summary:
+  Total Changes: 1
+  Breaking Changes: 1
+  Additions: 0
+  Removals: 0
+
+example.com/Foo:
+  ⚠️ [breaking] versions: Version 'v1alpha1' removed
+    Old: v1alpha1
+  v1:
+    No changes detected.
+`, output.String())
+}
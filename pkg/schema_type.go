@@ -1,12 +1,35 @@
 package pkg
 
-import "github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
 
 // Rendering provides extra rendering information of this schema.
 type Rendering struct {
 	// Group defines which group this schema should belong to. If empty
 	// the schema's version will be used as grouping information.
 	Group string
+	// Description is rendered under the name of the group.
+	Description string
+	// Minimal and Comments override the render options of this schema if set.
+	Minimal  *bool
+	Comments *bool
+}
+
+// Options returns the render options with the overrides of this schema applied.
+func (r Rendering) Options(opts RenderOpts) RenderOpts {
+	if r.Minimal != nil {
+		opts.Minimal = *r.Minimal
+	}
+
+	if r.Comments != nil {
+		opts.Comments = *r.Comments
+	}
+
+	return opts
 }
 
 // SchemaType is a wrapper around any kind of object that provide the following:
@@ -36,3 +59,51 @@ type Validation struct {
 	Name   string
 	Schema *v1beta1.JSONSchemaProps
 }
+
+// FilterVersions only keeps the versions of the schemas whose name is in names. Schemas without any
+// of them are dropped. All schemas are kept if names is empty. The given schemas aren't modified; the
+// filtered ones are shallow copies.
+func FilterVersions(crds []*SchemaType, names []string) []*SchemaType {
+	if len(names) == 0 {
+		return crds
+	}
+
+	result := make([]*SchemaType, 0, len(crds))
+
+	for _, crd := range crds {
+		if len(crd.Versions) == 0 {
+			if crd.Validation != nil && slices.Contains(names, crd.Validation.Name) {
+				result = append(result, crd)
+			}
+
+			continue
+		}
+
+		versions := make([]*CRDVersion, 0, len(crd.Versions))
+		for _, v := range crd.Versions {
+			if slices.Contains(names, v.Name) {
+				versions = append(versions, v)
+			}
+		}
+
+		if len(versions) > 0 {
+			filtered := *crd
+			filtered.Versions = versions
+			result = append(result, &filtered)
+		}
+	}
+
+	return result
+}
+
+// SortSchemaTypes sorts the schemas by their rendering group, API group and kind. The sort is stable, so
+// schemas that are equal in all of them keep the order of their sources.
+func SortSchemaTypes(crds []*SchemaType) {
+	slices.SortStableFunc(crds, func(a, b *SchemaType) int {
+		return cmp.Or(
+			cmp.Compare(a.Rendering.Group, b.Rendering.Group),
+			cmp.Compare(a.Group, b.Group),
+			cmp.Compare(a.Kind, b.Kind),
+		)
+	})
+}
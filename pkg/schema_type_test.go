@@ -0,0 +1,68 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterVersions(t *testing.T) {
+	crds := []*SchemaType{
+		{Kind: "Both", Versions: []*CRDVersion{{Name: "v1beta1"}, {Name: "v1"}}},
+		{Kind: "Beta", Versions: []*CRDVersion{{Name: "v1beta1"}}},
+		{Kind: "Validation", Validation: &Validation{Name: "v1"}},
+	}
+
+	result := FilterVersions(crds, []string{"v1"})
+
+	kinds := make([]string, 0, len(result))
+	for _, crd := range result {
+		kinds = append(kinds, crd.Kind)
+	}
+
+	assert.Equal(t, []string{"Both", "Validation"}, kinds)
+	assert.Equal(t, []*CRDVersion{{Name: "v1"}}, result[0].Versions)
+	assert.Len(t, crds[0].Versions, 2, "the given schemas aren't modified")
+	assert.Len(t, FilterVersions(crds, nil), 3)
+}
+
+func TestRenderingOptions(t *testing.T) {
+	yes := true
+
+	opts := Rendering{Minimal: &yes}.Options(RenderOpts{Comments: true})
+	assert.True(t, opts.Minimal)
+	assert.True(t, opts.Comments)
+
+	no := false
+	opts = Rendering{Comments: &no}.Options(RenderOpts{Comments: true})
+	assert.False(t, opts.Comments)
+}
+
+func TestSortSchemaTypes(t *testing.T) {
+	first := &SchemaType{Kind: "Same", Group: "a.example.com"}
+	second := &SchemaType{Kind: "Same", Group: "a.example.com"}
+	crds := []*SchemaType{
+		{Kind: "Alpha", Group: "b.example.com"},
+		first,
+		{Kind: "Zulu", Group: "a.example.com", Rendering: Rendering{Group: "docs"}},
+		{Kind: "Beta", Group: "a.example.com"},
+		second,
+	}
+
+	SortSchemaTypes(crds)
+
+	kinds := make([]string, 0, len(crds))
+	for _, crd := range crds {
+		kinds = append(kinds, crd.Group+"/"+crd.Kind)
+	}
+
+	assert.Equal(t, []string{
+		"a.example.com/Beta",
+		"a.example.com/Same",
+		"a.example.com/Same",
+		"b.example.com/Alpha",
+		"a.example.com/Zulu",
+	}, kinds)
+	assert.Same(t, first, crds[1], "equal schemas keep the order of their sources")
+	assert.Same(t, second, crds[2])
+}
@@ -93,19 +93,7 @@ func (r *ValidationReport) OutputText(w io.Writer) error {
 	}
 
 	wr.write(w, "Changes:\n")
-
-	for _, change := range r.Changes {
-		symbol := getChangeSymbol(change.Type)
-		wr.write(w, fmt.Sprintf("  %s [%s] %s: %s\n", symbol, change.Type, change.Path, change.Description))
-
-		if change.OldValue != "" {
-			wr.write(w, fmt.Sprintf("    Old: %s\n", change.OldValue))
-		}
-
-		if change.NewValue != "" {
-			wr.write(w, fmt.Sprintf("    New: %s\n", change.NewValue))
-		}
-	}
+	writeChanges(wr, w, "  ", r.Changes)
 
 	if wr.err != nil {
 		return fmt.Errorf("failed to write report: %w", wr.err)
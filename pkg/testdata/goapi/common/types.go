@@ -0,0 +1,7 @@
+package common
+
+// SecretReference points to a secret in the same namespace.
+type SecretReference struct {
+	// Name of the secret.
+	Name string `json:"name"`
+}
@@ -0,0 +1,3 @@
+// Package v1 contains the stable test API.
+// +groupName=test.example.com
+package v1
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"example.com/operator/api/common"
+)
+
+// Mode defines how fast a runner runs.
+// +kubebuilder:validation:Enum=Fast;Slow
+type Mode string
+
+// RunnerSpec defines the desired state of Runner.
+type RunnerSpec struct {
+	// Image to run.
+	//
+	// Must be a fully qualified reference.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[a-z./:-]+$`
+	Image string `json:"image"`
+
+	// Mode of the runner.
+	// +kubebuilder:default=Fast
+	Mode Mode `json:"mode,omitempty"`
+
+	// Replicas of the runner.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	Replicas *int32 `json:"replicas"`
+
+	// Args passed to the runner.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:items:MinLength=2
+	Args []string `json:"args,omitempty"`
+
+	// Labels added to the runner.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Port of the runner.
+	Port intstr.IntOrString `json:"port,omitempty"`
+
+	// +kubebuilder:validation:XValidation:rule="self.size() > 0",message="must not be empty"
+	Secret common.SecretReference `json:"secret"`
+
+	Internal string `json:"-"`
+}
+
+// RunnerStatus defines the observed state of Runner.
+type RunnerStatus struct {
+	// Conditions of the runner.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastRun is the time of the last run.
+	LastRun *metav1.Time `json:"lastRun,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// Runner runs things.
+type Runner struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RunnerSpec   `json:"spec,omitempty"`
+	Status RunnerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RunnerList contains a list of Runner.
+type RunnerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Runner `json:"items"`
+}
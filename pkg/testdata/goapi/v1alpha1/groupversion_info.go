@@ -0,0 +1,9 @@
+// Package v1alpha1 contains API Schema definitions for the test v1alpha1 API group.
+// +kubebuilder:object:generate=true
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var GroupVersion = schema.GroupVersion{Group: "test.example.com", Version: "v1alpha1"}
@@ -0,0 +1,36 @@
+// Code generated by cty. DO NOT EDIT.
+
+package v1alpha1
+
+// KrokCommand is the Schema for the krokcommands API
+#KrokCommand: {
+	// APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources
+	apiVersion: "delivery.krok.app/v1alpha1"
+	// Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds
+	kind: "KrokCommand"
+	metadata?: {...}
+	// KrokCommandSpec defines the desired state of KrokCommand
+	spec?: {
+		// CommandHasOutputToWrite if defined, it signals the underlying Job, to put its output into a generated and created secret.
+		commandHasOutputToWrite?: bool
+		// Dependencies defines a list of command names that this command depends on.
+		dependencies?: [...string]
+		// Enabled defines if this command can be executed or not.
+		enabled?: bool
+		// Image defines the image name and tag of the command example: krok-hook/slack-notification:v0.0.1
+		image: string
+		// Platforms holds all the platforms which this command supports.
+		platforms?: [...string]
+		// ReadInputFromSecret if defined, the command will take a list of key/value pairs in a secret and apply them as arguments to the command.
+		readInputFromSecret?: {
+			name: string
+			namespace: string
+		}
+		// Schedule of the command. example: 0 * * * * // follows cron job syntax.
+		schedule?: string
+		// SuspendStrategy can be used to modify the behaviour that is used when setting suspend to true.
+		suspendStrategy?: "ScaleDown" | "ScaleDownAndDeleteDisk"
+	}
+	// KrokCommandStatus defines the observed state of KrokCommand
+	status?: {...}
+}